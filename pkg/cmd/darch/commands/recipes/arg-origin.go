@@ -0,0 +1,47 @@
+package recipes
+
+import (
+	"fmt"
+
+	"github.com/godarch/darch/pkg/recipes"
+	"github.com/urfave/cli"
+)
+
+var argOriginCommand = cli.Command{
+	Name:      "arg-origin",
+	Usage:     "print the recipe in a recipe's chain that sets the winning value of a build arg",
+	ArgsUsage: "<recipe> <key>",
+	Action: func(clicontext *cli.Context) error {
+		var (
+			recipeName = clicontext.Args().First()
+			key        = clicontext.Args().Get(1)
+		)
+
+		if len(recipeName) == 0 || len(key) == 0 {
+			return fmt.Errorf("You must provide a recipe name and a build arg")
+		}
+
+		rs, err := getAllRecipes(clicontext)
+		if err != nil {
+			return err
+		}
+
+		current, ok := rs[recipeName]
+		if !ok {
+			return fmt.Errorf("Recipe %s doesn't exist", recipeName)
+		}
+
+		origin, ok, err := recipes.BuildArgOrigin(current, key, rs)
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			return fmt.Errorf("build arg %s isn't set by %s or its parents", key, recipeName)
+		}
+
+		fmt.Printf("%s %s=%s\n", origin.Name, key, origin.BuildArgs[key])
+
+		return nil
+	},
+}
@@ -0,0 +1,43 @@
+package recipes
+
+import (
+	"fmt"
+
+	"github.com/godarch/darch/pkg/recipes"
+	"github.com/urfave/cli"
+)
+
+var baseCommand = cli.Command{
+	Name:      "base",
+	Usage:     "print only the external image a recipe is ultimately built on",
+	ArgsUsage: "<recipe>",
+	Action: func(clicontext *cli.Context) error {
+		var (
+			recipeName = clicontext.Args().First()
+		)
+
+		if len(recipeName) == 0 {
+			return fmt.Errorf("You must provide a recipe name")
+		}
+
+		// Only the recipe's own chain needs to be valid.
+		rs, err := parseAllRecipes(clicontext)
+		if err = checkLoaded(err, recipeName); err != nil {
+			return err
+		}
+
+		current, ok := rs[recipeName]
+		if !ok {
+			return fmt.Errorf("Recipe %s doesn't exist", recipeName)
+		}
+
+		chain, err := recipes.ResolveChain(current, rs)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(chain[len(chain)-1].Inherits)
+
+		return nil
+	},
+}
@@ -0,0 +1,64 @@
+package recipes
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/godarch/darch/pkg/recipes"
+	"github.com/urfave/cli"
+)
+
+var blastRadiusCommand = cli.Command{
+	Name:      "blast-radius",
+	Usage:     "print the number of recipes inheriting from each recipe, and the percentage of all recipes that is",
+	ArgsUsage: "<recipes>*N",
+	Flags: []cli.Flag{
+		cli.BoolFlag{
+			Name:  "all",
+			Usage: "print all recipes",
+		},
+	},
+	Action: func(clicontext *cli.Context) error {
+		var (
+			recipeNames = clicontext.Args()
+			all         = clicontext.Bool("all")
+		)
+
+		if len(recipeNames) == 0 && !all {
+			return fmt.Errorf("You must provide recipe names or --all")
+		}
+
+		rs, err := getAllRecipes(clicontext)
+		if err != nil {
+			return err
+		}
+
+		if all {
+			recipeNames = make([]string, 0, len(rs))
+			for name := range rs {
+				recipeNames = append(recipeNames, name)
+			}
+		}
+		for _, recipeName := range recipeNames {
+			if _, ok := rs[recipeName]; !ok {
+				return fmt.Errorf("Recipe %s doesn't exist", recipeName)
+			}
+		}
+
+		descendants := recipes.AllDescendants(rs)
+
+		sort.Slice(recipeNames, func(i, j int) bool {
+			if len(descendants[recipeNames[i]]) != len(descendants[recipeNames[j]]) {
+				return len(descendants[recipeNames[i]]) > len(descendants[recipeNames[j]])
+			}
+			return recipeNames[i] < recipeNames[j]
+		})
+
+		for _, name := range recipeNames {
+			count := len(descendants[name])
+			fmt.Printf("%s\t%d\t%.1f%%\n", name, count, float64(count)*100/float64(len(rs)))
+		}
+
+		return nil
+	},
+}
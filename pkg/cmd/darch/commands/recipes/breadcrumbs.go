@@ -0,0 +1,64 @@
+package recipes
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/godarch/darch/pkg/recipes"
+	"github.com/urfave/cli"
+)
+
+var breadcrumbsCommand = cli.Command{
+	Name:      "breadcrumbs",
+	Usage:     "print the chain of a recipe on one line, from the external image down to the recipe",
+	ArgsUsage: "<recipe>",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "separator",
+			Usage: "the separator to put between each name",
+			Value: "›",
+		},
+		cli.BoolTFlag{
+			Name:  "include-external",
+			Usage: "start with the external image, use --include-external=false to start with the first recipe",
+		},
+	},
+	Action: func(clicontext *cli.Context) error {
+		var (
+			recipeName      = clicontext.Args().First()
+			separator       = clicontext.String("separator")
+			includeExternal = clicontext.BoolT("include-external")
+		)
+
+		if len(recipeName) == 0 {
+			return fmt.Errorf("You must provide a recipe name")
+		}
+
+		rs, err := getAllRecipes(clicontext)
+		if err != nil {
+			return err
+		}
+
+		current, ok := rs[recipeName]
+		if !ok {
+			return fmt.Errorf("Recipe %s doesn't exist", recipeName)
+		}
+
+		chain, err := recipes.ResolveChain(current, rs)
+		if err != nil {
+			return err
+		}
+
+		names := make([]string, 0, len(chain)+1)
+		if includeExternal {
+			names = append(names, chain[len(chain)-1].Inherits)
+		}
+		for i := len(chain) - 1; i >= 0; i-- {
+			names = append(names, chain[i].Name)
+		}
+
+		fmt.Println(strings.Join(names, " "+separator+" "))
+
+		return nil
+	},
+}
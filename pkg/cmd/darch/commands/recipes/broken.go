@@ -0,0 +1,56 @@
+package recipes
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/godarch/darch/pkg/recipes"
+	"github.com/godarch/darch/pkg/utils"
+	"github.com/urfave/cli"
+)
+
+var brokenCommand = cli.Command{
+	Name:  "broken",
+	Usage: "list the recipes involved in a validation problem",
+	Action: func(clicontext *cli.Context) error {
+		return runBroken(clicontext, os.Stdout)
+	},
+}
+
+// runBroken Writes each recipe involved in a validation problem, or that
+// couldn't be loaded, to w along with the kind of problem.
+func runBroken(clicontext *cli.Context, w io.Writer) error {
+	rs, err := parseAllRecipes(clicontext)
+	loadErr, ok := err.(*recipes.LoadError)
+	if !ok && err != nil {
+		return err
+	}
+
+	results := make([]string, 0)
+
+	if loadErr != nil {
+		for _, problem := range loadErr.Problems {
+			results = append(results, fmt.Sprintf("%s %s", problem.Recipes[0], problem.Kind))
+		}
+	}
+
+	for _, problem := range recipes.Validate(rs) {
+		for _, name := range problem.Recipes {
+			// Only report recipes that exist, not the missing parents they point at.
+			if _, ok := rs[name]; ok {
+				results = append(results, fmt.Sprintf("%s %s", name, problem.Kind))
+			}
+		}
+	}
+
+	results = utils.RemoveDuplicates(results)
+	sort.Strings(results)
+
+	for _, result := range results {
+		fmt.Fprintln(w, result)
+	}
+
+	return nil
+}
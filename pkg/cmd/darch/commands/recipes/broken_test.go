@@ -0,0 +1,32 @@
+package recipes
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/godarch/darch/pkg/utils"
+)
+
+func TestRunBroken(t *testing.T) {
+	recipesDir := path.Join(os.TempDir(), utils.NewID())
+	defer os.RemoveAll(recipesDir)
+
+	writeTestConfigurations(t, recipesDir, map[string]string{
+		"base":    `{"inherits": "external:archlinux/base"}`,
+		"broken":  `{"inherits": `,
+		"desktop": `{"inherits": "base"}`,
+		"orphan":  `{"inherits": "missing"}`,
+	})
+
+	var buffer bytes.Buffer
+	if err := runBroken(testContext(t, brokenCommand, recipesDir), &buffer); err != nil {
+		t.Fatalf("error running broken %v", err)
+	}
+
+	expected := "broken invalid\norphan missing-parent\n"
+	if buffer.String() != expected {
+		t.Fatalf("expected %q, got %q", expected, buffer.String())
+	}
+}
@@ -3,7 +3,6 @@ package recipes
 import (
 	"context"
 	"fmt"
-	"github.com/godarch/darch/pkg/recipes"
 	"github.com/godarch/darch/pkg/repository"
 	"github.com/urfave/cli"
 	"strings"
@@ -40,12 +39,16 @@ var buildCommand = cli.Command{
 			return fmt.Errorf("no recipes provided")
 		}
 
+		if err := requireRecipesDir(clicontext); err != nil {
+			return err
+		}
+
 		defaultTag, additionalTags, err := parseTags(tags)
 		if err != nil {
 			return err
 		}
 
-		allRecipes, err := recipes.GetAllRecipes(getRecipesDir(clicontext))
+		allRecipes, err := getAllRecipes(clicontext)
 		if err != nil {
 			return err
 		}
@@ -17,7 +17,7 @@ var builddepCommand = cli.Command{
 			recipeNames = clicontext.Args()
 		)
 
-		allRecipes, err := recipes.GetAllRecipes(getRecipesDir(clicontext))
+		allRecipes, err := getAllRecipes(clicontext)
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,64 @@
+package recipes
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/godarch/darch/pkg/recipes"
+	"github.com/urfave/cli"
+)
+
+var byArgCommand = cli.Command{
+	Name:      "by-arg",
+	Usage:     "list the recipes built with a build arg set to a value, or set at all when no value is given",
+	ArgsUsage: "<key>[=<value>]",
+	Action: func(clicontext *cli.Context) error {
+		return runByArg(clicontext, os.Stdout)
+	},
+}
+
+// runByArg Writes the recipes whose effective build args match the first
+// argument to w.
+func runByArg(clicontext *cli.Context, w io.Writer) error {
+	var (
+		query = clicontext.Args().First()
+	)
+
+	if len(query) == 0 {
+		return fmt.Errorf("You must provide a build arg")
+	}
+
+	key, value, hasValue := query, "", false
+	if i := strings.Index(query, "="); i >= 0 {
+		key, value, hasValue = query[:i], query[i+1:], true
+	}
+	if len(key) == 0 {
+		return fmt.Errorf("You must provide a build arg")
+	}
+
+	rs, err := getAllRecipes(clicontext)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0)
+	for name, r := range rs {
+		buildArgs, err := recipes.EffectiveBuildArgs(r, rs)
+		if err != nil {
+			return err
+		}
+		if current, ok := buildArgs[key]; ok && (!hasValue || current == value) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintln(w, name)
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package recipes
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/godarch/darch/pkg/utils"
+)
+
+func TestRunByArg(t *testing.T) {
+	recipesDir := path.Join(os.TempDir(), utils.NewID())
+	defer os.RemoveAll(recipesDir)
+
+	writeTestRecipes(t, recipesDir, map[string]string{
+		"base":    "external:archlinux/base",
+		"desktop": "base",
+		"tools":   "external:ubuntu",
+	})
+	configurations := map[string]string{
+		"server": `{"inherits": "base", "buildArgs": {"NODE_VERSION": "18"}}`,
+		"legacy": `{"inherits": "server", "buildArgs": {"NODE_VERSION": "16"}}`,
+		"web":    `{"inherits": "server"}`,
+	}
+	writeTestConfigurations(t, recipesDir, configurations)
+
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{"NODE_VERSION=18", "server\nweb\n"},
+		{"NODE_VERSION=16", "legacy\n"},
+		{"NODE_VERSION", "legacy\nserver\nweb\n"},
+		{"NODE_VERSION=20", ""},
+	}
+
+	for _, test := range tests {
+		var buffer bytes.Buffer
+		if err := runByArg(testContext(t, byArgCommand, recipesDir, test.query), &buffer); err != nil {
+			t.Fatalf("error running by-arg %v", err)
+		}
+		if buffer.String() != test.expected {
+			t.Fatalf("expected %q for %s, got %q", test.expected, test.query, buffer.String())
+		}
+	}
+}
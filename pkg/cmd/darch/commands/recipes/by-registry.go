@@ -0,0 +1,71 @@
+package recipes
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/godarch/darch/pkg/recipes"
+	"github.com/godarch/darch/pkg/utils"
+	"github.com/urfave/cli"
+)
+
+var byRegistryCommand = cli.Command{
+	Name:  "by-registry",
+	Usage: "count the recipes built on each registry, by the external image at the root of their chain",
+	Flags: []cli.Flag{
+		cli.BoolFlag{
+			Name:  "names",
+			Usage: "also print the names of the recipes under each registry",
+		},
+	},
+	Action: func(clicontext *cli.Context) error {
+		var (
+			names = clicontext.Bool("names")
+		)
+
+		rs, err := getAllRecipes(clicontext)
+		if err != nil {
+			return err
+		}
+
+		groups, err := groupByRegistry(rs)
+		if err != nil {
+			return err
+		}
+
+		registries := make([]string, 0, len(groups))
+		for registry := range groups {
+			registries = append(registries, registry)
+		}
+		sort.Strings(registries)
+
+		for _, registry := range registries {
+			fmt.Printf("%s %d\n", registry, len(groups[registry]))
+			if names {
+				for _, name := range groups[registry] {
+					fmt.Printf("  %s\n", name)
+				}
+			}
+		}
+
+		return nil
+	},
+}
+
+// groupByRegistry Groups the sorted names of the recipes by the registry of
+// the external image at the root of their chain.
+func groupByRegistry(rs map[string]recipes.Recipe) (map[string][]string, error) {
+	result := make(map[string][]string, 0)
+	for _, r := range rs {
+		chain, err := recipes.ResolveChain(r, rs)
+		if err != nil {
+			return nil, err
+		}
+		registry := utils.ImageRegistry(chain[len(chain)-1].Inherits)
+		result[registry] = append(result[registry], r.Name)
+	}
+	for _, group := range result {
+		sort.Strings(group)
+	}
+	return result, nil
+}
@@ -0,0 +1,105 @@
+package recipes
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/godarch/darch/pkg/recipes"
+	"github.com/godarch/darch/pkg/utils"
+	"github.com/urfave/cli"
+)
+
+var cacheKeysCommand = cli.Command{
+	Name:      "cache-keys",
+	Usage:     "print the chain-hash of recipes, optionally only those that changed since a saved key file",
+	ArgsUsage: "<recipes>*N",
+	Flags: []cli.Flag{
+		cli.BoolFlag{
+			Name:  "all",
+			Usage: "print keys for all recipes",
+		},
+		cli.StringFlag{
+			Name:  "compare",
+			Usage: "a key file previously saved from this command, only recipes whose key changed will be printed",
+		},
+	},
+	Action: func(clicontext *cli.Context) error {
+		var (
+			recipeNames = clicontext.Args()
+			all         = clicontext.Bool("all")
+			compare     = clicontext.String("compare")
+		)
+
+		if len(recipeNames) == 0 && !all {
+			return fmt.Errorf("You must provide recipe names or --all")
+		}
+
+		rs, err := getAllRecipes(clicontext)
+		if err != nil {
+			return err
+		}
+
+		hashes, err := recipes.ChainHashes(rs)
+		if err != nil {
+			return err
+		}
+
+		if !all {
+			selected := make(map[string]string, 0)
+			for _, recipeName := range recipeNames {
+				hash, ok := hashes[recipeName]
+				if !ok {
+					return fmt.Errorf("Recipe %s doesn't exist", recipeName)
+				}
+				selected[recipeName] = hash
+			}
+			hashes = selected
+		}
+
+		if len(compare) > 0 {
+			previous, err := readCacheKeys(compare)
+			if err != nil {
+				return err
+			}
+			for _, name := range recipes.ChangedChainHashes(previous, hashes) {
+				fmt.Println(name)
+			}
+			return nil
+		}
+
+		names := make([]string, 0)
+		for name := range hashes {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			fmt.Printf("%s %s\n", name, hashes[name])
+		}
+
+		return nil
+	},
+}
+
+// readCacheKeys Reads a file in the "<recipe> <hash>" format printed by cache-keys.
+func readCacheKeys(file string) (map[string]string, error) {
+	lines, err := utils.GetFileLines(file)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]string, 0)
+	for i, line := range lines {
+		if len(strings.TrimSpace(line)) == 0 {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid key at %s:%d", file, i+1)
+		}
+		result[fields[0]] = fields[1]
+	}
+
+	return result, nil
+}
@@ -0,0 +1,42 @@
+package recipes
+
+import (
+	"fmt"
+
+	"github.com/godarch/darch/pkg/recipes"
+	"github.com/urfave/cli"
+)
+
+var chainHashCommand = cli.Command{
+	Name:      "chain-hash",
+	Usage:     "print a hash of a recipe's inheritance chain, usable as a cache key",
+	ArgsUsage: "<recipe>",
+	Action: func(clicontext *cli.Context) error {
+		var (
+			recipeName = clicontext.Args().First()
+		)
+
+		if len(recipeName) == 0 {
+			return fmt.Errorf("You must provide a recipe name")
+		}
+
+		rs, err := getAllRecipes(clicontext)
+		if err != nil {
+			return err
+		}
+
+		current, ok := rs[recipeName]
+		if !ok {
+			return fmt.Errorf("Recipe %s doesn't exist", recipeName)
+		}
+
+		hash, err := recipes.ChainHash(current, rs)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(hash)
+
+		return nil
+	},
+}
@@ -2,10 +2,13 @@ package recipes
 
 import (
 	"fmt"
-	"log"
+	"io"
+	"os"
 	"sort"
+	"strings"
 
 	"github.com/godarch/darch/pkg/recipes"
+	"github.com/godarch/darch/pkg/utils"
 	"github.com/urfave/cli"
 )
 
@@ -16,43 +19,160 @@ var childrenCommand = cli.Command{
 		cli.BoolFlag{
 			Name: "reverse",
 		},
+		cli.BoolFlag{
+			Name:  "flat",
+			Usage: "only print the names of the children, without the header for an external image or indentation",
+		},
+		cli.BoolFlag{
+			Name:  "recursive, all",
+			Usage: "also print the children of each child, indented by depth, or with --flat level by level (deepest first with --reverse)",
+		},
+		cli.IntFlag{
+			Name:  "max-depth",
+			Usage: "with --recursive, the number of levels of children to print, 0 prints none",
+		},
 	},
 	Action: func(clicontext *cli.Context) error {
-		var (
-			recipeName = clicontext.Args().First()
-			reverse    = clicontext.Bool("reverse")
-		)
+		return runChildren(clicontext, os.Stdout)
+	},
+}
 
-		if len(recipeName) == 0 {
-			return fmt.Errorf("You must provide a recipe name")
-		}
+// runChildren Writes the children of the recipe or external image named by
+// the first argument to w.
+func runChildren(clicontext *cli.Context, w io.Writer) error {
+	var (
+		recipeName = clicontext.Args().First()
+		reverse    = clicontext.Bool("reverse")
+		flat       = clicontext.Bool("flat")
+		recursive  = clicontext.Bool("recursive")
+		maxDepth   = -1
+	)
 
-		rs, err := recipes.GetAllRecipes(getRecipesDir(clicontext))
-		if err != nil {
-			return err
+	if clicontext.IsSet("max-depth") {
+		if maxDepth = clicontext.Int("max-depth"); maxDepth < 0 {
+			return fmt.Errorf("--max-depth must not be negative")
 		}
+	}
+
+	if recursive && reverse && !flat {
+		return fmt.Errorf("--recursive --reverse lists the deepest children first, which needs --flat")
+	}
 
-		current, ok := rs[recipeName]
-		if !ok {
-			return fmt.Errorf("Recipe %s doesn't exist", recipeName)
+	if len(recipeName) == 0 {
+		return fmt.Errorf("You must provide a recipe name")
+	}
+
+	rs, err := getAllRecipes(clicontext)
+	if err != nil {
+		return err
+	}
+
+	externalImage := strings.TrimPrefix(recipeName, "external:")
+	external := externalImage != recipeName
+	if _, ok := rs[recipeName]; !ok {
+		external = true
+	}
+
+	results := make([]string, 0)
+
+	for _, r := range rs {
+		if external && r.InheritsExternal && r.Inherits == externalImage {
+			results = append(results, r.Name)
+		} else if !external && !r.InheritsExternal && r.Inherits == recipeName {
+			results = append(results, r.Name)
 		}
+	}
 
-		results := make([]string, 0)
+	if external && len(results) == 0 {
+		return fmt.Errorf("Recipe %s doesn't exist", recipeName)
+	}
 
-		for _, r := range rs {
-			if r.Inherits == current.Name {
-				results = append(results, r.Name)
-			}
+	order := func(names []string) {
+		sort.Strings(names)
+		if reverse {
+			sort.Sort(sort.Reverse(sort.StringSlice(names)))
 		}
+	}
+	order(results)
 
+	if recursive && flat {
+		results = descendantsByLevel(results, recipes.DirectChildren(rs), maxDepth)
 		if reverse {
-			sort.Sort(sort.Reverse(sort.StringSlice(results)))
+			results = utils.Reverse(results)
 		}
+	} else if recursive {
+		results = withDescendants(results, recipes.DirectChildren(rs), maxDepth, order)
+	}
 
+	if flat {
 		for _, result := range results {
-			log.Println(result)
+			fmt.Fprintln(w, result)
 		}
+		return nil
+	}
 
+	if external {
+		fmt.Fprintln(w, "external:"+externalImage)
+		for _, result := range results {
+			fmt.Fprintln(w, "  "+result)
+		}
 		return nil
-	},
+	}
+
+	for _, result := range results {
+		fmt.Fprintln(w, result)
+	}
+
+	return nil
+}
+
+// withDescendants Returns the names followed by each of their descendants,
+// indented by their depth below the names. The names are the first level,
+// and up to maxDepth levels are returned, or all of them when negative.
+func withDescendants(names []string, children map[string][]string, maxDepth int, order func([]string)) []string {
+	result := make([]string, 0)
+
+	var walk func(names []string, depth int)
+	walk = func(names []string, depth int) {
+		if maxDepth >= 0 && depth > maxDepth {
+			return
+		}
+		for _, name := range names {
+			result = append(result, strings.Repeat("  ", depth-1)+name)
+			if maxDepth < 0 || depth < maxDepth {
+				next := append([]string(nil), children[name]...)
+				order(next)
+				walk(next, depth+1)
+			}
+		}
+	}
+	walk(names, 1)
+
+	return result
+}
+
+// descendantsByLevel Returns the names followed by each of their
+// descendants, one level at a time and sorted within each level. The names
+// are the first level, and up to maxDepth levels are returned, or all of
+// them when negative. Each name is only included once.
+func descendantsByLevel(names []string, children map[string][]string, maxDepth int) []string {
+	result := make([]string, 0)
+	visited := make(map[string]bool, 0)
+
+	level := append([]string(nil), names...)
+	for depth := 1; len(level) > 0 && (maxDepth < 0 || depth <= maxDepth); depth++ {
+		sort.Strings(level)
+		next := make([]string, 0)
+		for _, name := range level {
+			if visited[name] {
+				continue
+			}
+			visited[name] = true
+			result = append(result, name)
+			next = append(next, children[name]...)
+		}
+		level = next
+	}
+
+	return result
 }
@@ -0,0 +1,71 @@
+package recipes
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/godarch/darch/pkg/utils"
+)
+
+func TestDescendantsByLevel(t *testing.T) {
+	children := map[string][]string{
+		"base":    {"server", "desktop"},
+		"server":  {"web", "db"},
+		"desktop": {"kde"},
+	}
+
+	expected := []string{"base", "desktop", "server", "db", "kde", "web"}
+	if result := descendantsByLevel([]string{"base"}, children, -1); !reflect.DeepEqual(result, expected) {
+		t.Fatalf("expected %v, got %v", expected, result)
+	}
+
+	expected = []string{"desktop", "server"}
+	if result := descendantsByLevel([]string{"server", "desktop", "server"}, children, 1); !reflect.DeepEqual(result, expected) {
+		t.Fatalf("expected %v, got %v", expected, result)
+	}
+}
+
+func TestRunChildren(t *testing.T) {
+	recipesDir := path.Join(os.TempDir(), utils.NewID())
+	defer os.RemoveAll(recipesDir)
+
+	writeTestRecipes(t, recipesDir, map[string]string{
+		"base":    "external:archlinux/base",
+		"server":  "base",
+		"web":     "server",
+		"desktop": "base",
+	})
+
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"base"}, "desktop\nserver\n"},
+		{[]string{"external:archlinux/base"}, "external:archlinux/base\n  base\n"},
+		{[]string{"--recursive", "base"}, "desktop\nserver\n  web\n"},
+		{[]string{"--recursive", "--max-depth", "1", "base"}, "desktop\nserver\n"},
+		{[]string{"--recursive", "--max-depth", "0", "base"}, ""},
+		{[]string{"--recursive", "--flat", "--max-depth", "1", "base"}, "desktop\nserver\n"},
+		{[]string{"--recursive", "--flat", "--reverse", "base"}, "web\nserver\ndesktop\n"},
+	}
+
+	for _, test := range tests {
+		var buffer bytes.Buffer
+		if err := runChildren(testContext(t, childrenCommand, recipesDir, test.args...), &buffer); err != nil {
+			t.Fatalf("error running children %v", err)
+		}
+		if buffer.String() != test.expected {
+			t.Fatalf("expected %q for %v, got %q", test.expected, test.args, buffer.String())
+		}
+	}
+
+	var buffer bytes.Buffer
+	err := runChildren(testContext(t, childrenCommand, recipesDir, "--recursive", "--reverse", "base"), &buffer)
+	if err == nil || !strings.Contains(err.Error(), "needs --flat") {
+		t.Fatalf("expected an error for --recursive --reverse without --flat, got %v", err)
+	}
+}
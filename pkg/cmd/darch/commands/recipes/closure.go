@@ -0,0 +1,60 @@
+package recipes
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/godarch/darch/pkg/recipes"
+	"github.com/urfave/cli"
+)
+
+var closureCommand = cli.Command{
+	Name:  "closure",
+	Usage: "print every ancestor and descendant pair of recipes, which can be large for deep trees",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "format",
+			Usage: "the output format (csv)",
+			Value: "csv",
+		},
+	},
+	Action: func(clicontext *cli.Context) error {
+		var (
+			format = clicontext.String("format")
+		)
+
+		if format != "csv" {
+			return fmt.Errorf("unknown format %s, must be csv", format)
+		}
+
+		rs, err := getAllRecipes(clicontext)
+		if err != nil {
+			return err
+		}
+
+		descendants := recipes.AllDescendants(rs)
+
+		names := make([]string, 0, len(rs))
+		for name := range rs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		w := csv.NewWriter(os.Stdout)
+		if err = w.Write([]string{"ancestor", "descendant"}); err != nil {
+			return err
+		}
+		for _, name := range names {
+			for _, descendant := range descendants[name] {
+				if err = w.Write([]string{name, descendant}); err != nil {
+					return err
+				}
+			}
+		}
+		w.Flush()
+
+		return w.Error()
+	},
+}
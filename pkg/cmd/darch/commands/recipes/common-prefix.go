@@ -0,0 +1,70 @@
+package recipes
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/godarch/darch/pkg/recipes"
+	"github.com/godarch/darch/pkg/utils"
+	"github.com/urfave/cli"
+)
+
+var commonPrefixCommand = cli.Command{
+	Name:      "common-prefix",
+	Usage:     "print the chain of parents shared by recipes, from the external image down to where they diverge",
+	ArgsUsage: "<recipe> <recipe> <recipes>*N",
+	Action: func(clicontext *cli.Context) error {
+		var (
+			recipeNames = clicontext.Args()
+		)
+
+		if len(recipeNames) < 2 {
+			return fmt.Errorf("You must provide at least two recipe names")
+		}
+
+		rs, err := getAllRecipes(clicontext)
+		if err != nil {
+			return err
+		}
+
+		selected := make([]recipes.Recipe, 0, len(recipeNames))
+		for _, recipeName := range recipeNames {
+			current, ok := rs[recipeName]
+			if !ok {
+				return fmt.Errorf("Recipe %s doesn't exist", recipeName)
+			}
+			selected = append(selected, current)
+		}
+
+		external, prefix, err := recipes.CommonChainPrefix(selected, rs)
+		if err != nil {
+			return err
+		}
+
+		path := []string{"external:" + external}
+		for _, r := range prefix {
+			path = append(path, r.Name)
+		}
+		fmt.Println(strings.Join(path, " -> "))
+
+		// The first recipe below the prefix in each chain, where they diverge.
+		branches := make([]string, 0)
+		for _, r := range selected {
+			chain, err := recipes.ResolveChain(r, rs)
+			if err != nil {
+				return err
+			}
+			if len(chain) > len(prefix) {
+				branches = append(branches, chain[len(chain)-len(prefix)-1].Name)
+			}
+		}
+		branches = utils.RemoveDuplicates(branches)
+		sort.Strings(branches)
+		if len(branches) > 0 {
+			fmt.Printf("diverges after %s into %s\n", path[len(path)-1], strings.Join(branches, ", "))
+		}
+
+		return nil
+	},
+}
@@ -0,0 +1,206 @@
+package recipes
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/godarch/darch/pkg/recipes"
+	"github.com/godarch/darch/pkg/utils"
+	"github.com/urfave/cli"
+)
+
+var compareTreesCommand = cli.Command{
+	Name:      "compare-trees",
+	Usage:     "show the trees below two recipes side by side, lining up recipes with the same name relative to each tree's root, and marking recipes only one of them has with *",
+	ArgsUsage: "<recipe> <recipe>",
+	Action: func(clicontext *cli.Context) error {
+		var (
+			first  = clicontext.Args().First()
+			second = clicontext.Args().Get(1)
+		)
+
+		if len(first) == 0 || len(second) == 0 {
+			return fmt.Errorf("You must provide two recipe names")
+		}
+
+		rs, err := getAllRecipes(clicontext)
+		if err != nil {
+			return err
+		}
+
+		for _, name := range []string{first, second} {
+			if _, ok := rs[name]; !ok {
+				return fmt.Errorf("Recipe %s doesn't exist", name)
+			}
+		}
+
+		left, right := renderComparedTree(buildComparedTree(first, second, recipes.DirectChildren(rs)))
+
+		width, _ := terminalWidth()
+		fmt.Print(renderSideBySide(left, right, width))
+
+		return nil
+	},
+}
+
+// comparedNode A row of compare-trees, with the recipe from each tree that
+// has the same name relative to the root of its tree. A side is empty when
+// only the other tree has the recipe.
+type comparedNode struct {
+	Left  string
+	Right string
+	Items []comparedNode
+}
+
+// relativeName Returns the name with the root's name, and the separator
+// after it, removed. So team-a-web under team-a matches team-b-web under
+// team-b as web.
+func relativeName(root string, name string) string {
+	if !strings.HasPrefix(name, root) {
+		return name
+	}
+	return strings.TrimLeft(strings.TrimPrefix(name, root), "-_./")
+}
+
+// buildComparedTree Builds the rows comparing the trees below two recipes,
+// matching children by their name relative to their tree's root.
+func buildComparedTree(first string, second string, children map[string][]string) comparedNode {
+	var build func(left string, right string) comparedNode
+	build = func(left string, right string) comparedNode {
+		node := comparedNode{Left: left, Right: right}
+
+		keys := make([]string, 0)
+		lefts := make(map[string]string, 0)
+		rights := make(map[string]string, 0)
+		if len(left) > 0 {
+			for _, child := range children[left] {
+				key := relativeName(first, child)
+				lefts[key] = child
+				keys = append(keys, key)
+			}
+		}
+		if len(right) > 0 {
+			for _, child := range children[right] {
+				key := relativeName(second, child)
+				rights[key] = child
+				keys = append(keys, key)
+			}
+		}
+		keys = utils.RemoveDuplicates(keys)
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			node.Items = append(node.Items, build(lefts[key], rights[key]))
+		}
+		return node
+	}
+
+	return build(first, second)
+}
+
+// renderComparedTree Renders each side of the rows as a tree, with a blank
+// line where a side doesn't have the recipe, so the rows line up. Recipes
+// only one side has are marked with a *.
+func renderComparedTree(root comparedNode) (string, string) {
+	var left, right bytes.Buffer
+
+	label := func(name string, other string) string {
+		if len(other) == 0 {
+			return name + " *"
+		}
+		return name
+	}
+	line := func(spaces []bool, last bool, name string) string {
+		var prefix bytes.Buffer
+		for _, space := range spaces {
+			if space {
+				prefix.WriteString("    ")
+			} else {
+				prefix.WriteString("│   ")
+			}
+		}
+		if last {
+			prefix.WriteString("└── ")
+		} else {
+			prefix.WriteString("├── ")
+		}
+		return prefix.String() + name
+	}
+	// isLast Whether no sibling after i is on the side.
+	isLast := func(items []comparedNode, i int, side func(comparedNode) string) bool {
+		for _, item := range items[i+1:] {
+			if len(side(item)) > 0 {
+				return false
+			}
+		}
+		return true
+	}
+	leftOf := func(node comparedNode) string { return node.Left }
+	rightOf := func(node comparedNode) string { return node.Right }
+
+	var walk func(items []comparedNode, leftSpaces []bool, rightSpaces []bool)
+	walk = func(items []comparedNode, leftSpaces []bool, rightSpaces []bool) {
+		for i, item := range items {
+			leftLast, rightLast := isLast(items, i, leftOf), isLast(items, i, rightOf)
+			if len(item.Left) > 0 {
+				left.WriteString(line(leftSpaces, leftLast, label(item.Left, item.Right)))
+			}
+			left.WriteString("\n")
+			if len(item.Right) > 0 {
+				right.WriteString(line(rightSpaces, rightLast, label(item.Right, item.Left)))
+			}
+			right.WriteString("\n")
+			walk(item.Items,
+				append(append([]bool(nil), leftSpaces...), leftLast),
+				append(append([]bool(nil), rightSpaces...), rightLast))
+		}
+	}
+
+	left.WriteString(root.Left + "\n")
+	right.WriteString(root.Right + "\n")
+	walk(root.Items, nil, nil)
+
+	return left.String(), right.String()
+}
+
+// renderSideBySide Renders two blocks of text in columns, row by row. The
+// blocks are stacked instead when the columns wouldn't fit in width. A
+// width of zero is unlimited.
+func renderSideBySide(left string, right string, width int) string {
+	leftLines := strings.Split(strings.TrimRight(left, "\n"), "\n")
+	rightLines := strings.Split(strings.TrimRight(right, "\n"), "\n")
+
+	leftWidth, rightWidth := 0, 0
+	for _, line := range leftLines {
+		if n := utf8.RuneCountInString(line); n > leftWidth {
+			leftWidth = n
+		}
+	}
+	for _, line := range rightLines {
+		if n := utf8.RuneCountInString(line); n > rightWidth {
+			rightWidth = n
+		}
+	}
+
+	const separator = " | "
+	if width > 0 && leftWidth+len(separator)+rightWidth > width {
+		return strings.Join(leftLines, "\n") + "\n\n" + strings.Join(rightLines, "\n") + "\n"
+	}
+
+	var buffer bytes.Buffer
+	for i := 0; i < len(leftLines) || i < len(rightLines); i++ {
+		l, r := "", ""
+		if i < len(leftLines) {
+			l = leftLines[i]
+		}
+		if i < len(rightLines) {
+			r = rightLines[i]
+		}
+		buffer.WriteString(strings.TrimRight(l+strings.Repeat(" ", leftWidth-utf8.RuneCountInString(l))+separator+r, " "))
+		buffer.WriteString("\n")
+	}
+	return buffer.String()
+}
@@ -0,0 +1,53 @@
+package recipes
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRenderSideBySide(t *testing.T) {
+	left := "web\n└── api\n"
+	right := "desktop\n├── kde *\n└── gnome *\n"
+
+	expected := "web     | desktop\n└── api | ├── kde *\n        | └── gnome *\n"
+	if output := renderSideBySide(left, right, 0); output != expected {
+		t.Fatalf("expected %q, got %q", expected, output)
+	}
+
+	stacked := "web\n└── api\n\ndesktop\n├── kde *\n└── gnome *\n"
+	if output := renderSideBySide(left, right, 10); output != stacked {
+		t.Fatalf("expected %q, got %q", stacked, output)
+	}
+}
+
+func TestBuildComparedTree(t *testing.T) {
+	children := map[string][]string{
+		"team-a":     {"team-a-db", "team-a-web"},
+		"team-a-web": {"team-a-web-api"},
+		"team-b":     {"team-b-cache", "team-b-web"},
+		"team-b-web": {"team-b-web-api"},
+	}
+
+	tree := buildComparedTree("team-a", "team-b", children)
+
+	expected := comparedNode{Left: "team-a", Right: "team-b", Items: []comparedNode{
+		{Right: "team-b-cache"},
+		{Left: "team-a-db"},
+		{Left: "team-a-web", Right: "team-b-web", Items: []comparedNode{
+			{Left: "team-a-web-api", Right: "team-b-web-api"},
+		}},
+	}}
+	if !reflect.DeepEqual(tree, expected) {
+		t.Fatalf("expected %v, got %v", expected, tree)
+	}
+
+	left, right := renderComparedTree(tree)
+	expectedLeft := "team-a\n\n├── team-a-db *\n└── team-a-web\n    └── team-a-web-api\n"
+	expectedRight := "team-b\n├── team-b-cache *\n\n└── team-b-web\n    └── team-b-web-api\n"
+	if left != expectedLeft {
+		t.Fatalf("expected %q, got %q", expectedLeft, left)
+	}
+	if right != expectedRight {
+		t.Fatalf("expected %q, got %q", expectedRight, right)
+	}
+}
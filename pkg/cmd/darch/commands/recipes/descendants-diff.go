@@ -0,0 +1,64 @@
+package recipes
+
+import (
+	"fmt"
+
+	"github.com/godarch/darch/pkg/recipes"
+	"github.com/urfave/cli"
+)
+
+var descendantsDiffCommand = cli.Command{
+	Name:      "descendants-diff",
+	Usage:     "print the descendants of each of two recipes that aren't descendants of the other",
+	ArgsUsage: "<recipe> <recipe>",
+	Action: func(clicontext *cli.Context) error {
+		var (
+			first  = clicontext.Args().First()
+			second = clicontext.Args().Get(1)
+		)
+
+		if len(first) == 0 || len(second) == 0 {
+			return fmt.Errorf("You must provide two recipe names")
+		}
+
+		rs, err := getAllRecipes(clicontext)
+		if err != nil {
+			return err
+		}
+
+		for _, name := range []string{first, second} {
+			if _, ok := rs[name]; !ok {
+				return fmt.Errorf("Recipe %s doesn't exist", name)
+			}
+		}
+
+		descendants := recipes.AllDescendants(rs)
+
+		fmt.Printf("only below %s:\n", first)
+		for _, name := range difference(descendants[first], descendants[second]) {
+			fmt.Printf("  %s\n", name)
+		}
+		fmt.Println()
+		fmt.Printf("only below %s:\n", second)
+		for _, name := range difference(descendants[second], descendants[first]) {
+			fmt.Printf("  %s\n", name)
+		}
+
+		return nil
+	},
+}
+
+// difference Returns the names in a that aren't in b, keeping the order of a.
+func difference(a []string, b []string) []string {
+	inB := make(map[string]bool, len(b))
+	for _, name := range b {
+		inB[name] = true
+	}
+	result := make([]string, 0)
+	for _, name := range a {
+		if !inB[name] {
+			result = append(result, name)
+		}
+	}
+	return result
+}
@@ -0,0 +1,40 @@
+package recipes
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/godarch/darch/pkg/recipes"
+	"github.com/urfave/cli"
+)
+
+var diameterCommand = cli.Command{
+	Name:  "diameter",
+	Usage: "print the longest distance from an external image down to a recipe, and the chain with it",
+	Action: func(clicontext *cli.Context) error {
+		rs, err := getAllRecipes(clicontext)
+		if err != nil {
+			return err
+		}
+
+		chain, err := recipes.LongestChain(rs)
+		if err != nil {
+			return err
+		}
+
+		if len(chain) == 0 {
+			fmt.Println(0)
+			return nil
+		}
+
+		names := []string{chain[len(chain)-1].Inherits}
+		for i := len(chain) - 1; i >= 0; i-- {
+			names = append(names, chain[i].Name)
+		}
+
+		fmt.Println(len(chain))
+		fmt.Println(strings.Join(names, " -> "))
+
+		return nil
+	},
+}
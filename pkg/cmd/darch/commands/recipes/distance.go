@@ -0,0 +1,56 @@
+package recipes
+
+import (
+	"fmt"
+
+	"github.com/godarch/darch/pkg/recipes"
+	"github.com/urfave/cli"
+)
+
+var distanceCommand = cli.Command{
+	Name:  "distance",
+	Usage: "print the number of inheritance hops from a recipe up to one of its parents",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "from",
+			Usage: "the recipe to start from",
+		},
+		cli.StringFlag{
+			Name:  "to",
+			Usage: "the parent recipe, or external image, to count up to",
+		},
+	},
+	Action: func(clicontext *cli.Context) error {
+		var (
+			from = clicontext.String("from")
+			to   = clicontext.String("to")
+		)
+
+		if len(from) == 0 || len(to) == 0 {
+			return fmt.Errorf("You must provide --from and --to")
+		}
+
+		rs, err := getAllRecipes(clicontext)
+		if err != nil {
+			return err
+		}
+
+		current, ok := rs[from]
+		if !ok {
+			return fmt.Errorf("Recipe %s doesn't exist", from)
+		}
+
+		distance, err := recipes.Distance(current, to, rs)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(distance)
+
+		if distance < 0 {
+			return fmt.Errorf("%s is not a parent of %s", to, from)
+		}
+
+		return nil
+	},
+}
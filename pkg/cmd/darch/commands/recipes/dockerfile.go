@@ -0,0 +1,89 @@
+package recipes
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/godarch/darch/pkg/recipes"
+	"github.com/urfave/cli"
+)
+
+var dockerfileCommand = cli.Command{
+	Name:      "dockerfile",
+	Usage:     "print a Dockerfile roughly equivalent to building a recipe and its parents",
+	ArgsUsage: "<recipe>",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "output",
+			Usage: "a file to write the Dockerfile to, instead of stdout",
+		},
+	},
+	Action: func(clicontext *cli.Context) error {
+		var (
+			recipeName = clicontext.Args().First()
+			output     = clicontext.String("output")
+		)
+
+		if len(recipeName) == 0 {
+			return fmt.Errorf("You must provide a recipe name")
+		}
+
+		rs, err := getAllRecipes(clicontext)
+		if err != nil {
+			return err
+		}
+
+		current, ok := rs[recipeName]
+		if !ok {
+			return fmt.Errorf("Recipe %s doesn't exist", recipeName)
+		}
+
+		chain, err := recipes.ResolveChain(current, rs)
+		if err != nil {
+			return err
+		}
+
+		dockerfile := renderDockerfile(chain)
+
+		if len(output) > 0 {
+			return ioutil.WriteFile(output, []byte(dockerfile), 0644)
+		}
+
+		fmt.Print(dockerfile)
+
+		return nil
+	},
+}
+
+// renderDockerfile Renders a chain, as returned by recipes.ResolveChain,
+// as a Dockerfile. Each recipe's build args are declared after its
+// parent's, so the recipe's values win.
+func renderDockerfile(chain []recipes.Recipe) string {
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf("FROM %s\n", chain[len(chain)-1].Inherits))
+
+	for i := len(chain) - 1; i >= 0; i-- {
+		r := chain[i]
+		buffer.WriteString(fmt.Sprintf("\n# recipe %s\n", r.Name))
+
+		keys := make([]string, 0, len(r.BuildArgs))
+		for key := range r.BuildArgs {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			value := r.BuildArgs[key]
+			if len(value) == 0 || strings.ContainsAny(value, " \t\"'\\$") {
+				value = strconv.Quote(value)
+			}
+			buffer.WriteString(fmt.Sprintf("ARG %s=%s\n", key, value))
+		}
+	}
+
+	return buffer.String()
+}
@@ -0,0 +1,28 @@
+package recipes
+
+import (
+	"testing"
+
+	"github.com/godarch/darch/pkg/recipes"
+)
+
+func TestRenderDockerfile(t *testing.T) {
+	chain := []recipes.Recipe{
+		{Name: "web", Inherits: "base", BuildArgs: map[string]string{"PORT": "8080", "LOCALE": "de_DE"}},
+		{Name: "base", Inherits: "archlinux/base", InheritsExternal: true, BuildArgs: map[string]string{"LOCALE": "en US"}},
+	}
+
+	expected := `FROM archlinux/base
+
+# recipe base
+ARG LOCALE="en US"
+
+# recipe web
+ARG LOCALE=de_DE
+ARG PORT=8080
+`
+
+	if dockerfile := renderDockerfile(chain); dockerfile != expected {
+		t.Fatalf("expected %s, got %s", expected, dockerfile)
+	}
+}
@@ -0,0 +1,56 @@
+package recipes
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/godarch/darch/pkg/recipes"
+	"github.com/urfave/cli"
+)
+
+var etaCommand = cli.Command{
+	Name:      "eta",
+	Usage:     "estimate how long rebuilding a recipe and everything inheriting it takes, from the buildSeconds of each recipe",
+	ArgsUsage: "<recipe>",
+	Action: func(clicontext *cli.Context) error {
+		var (
+			recipeName = clicontext.Args().First()
+		)
+
+		if len(recipeName) == 0 {
+			return fmt.Errorf("You must provide a recipe name")
+		}
+
+		rs, err := getAllRecipes(clicontext)
+		if err != nil {
+			return err
+		}
+
+		current, ok := rs[recipeName]
+		if !ok {
+			return fmt.Errorf("Recipe %s doesn't exist", recipeName)
+		}
+
+		selected := map[string]bool{current.Name: true}
+		for _, descendant := range recipes.Descendants(current, rs, -1) {
+			selected[descendant] = true
+		}
+
+		waves, err := recipes.BuildWaves(rs)
+		if err != nil {
+			return err
+		}
+		waves = filterWaves(waves, selected)
+
+		estimate := recipes.EstimateBuildTime(waves, rs)
+
+		fmt.Printf("parallel: %ds (%d waves)\n", estimate.CriticalPathSeconds, len(waves))
+		fmt.Printf("serial: %ds (%d recipes)\n", estimate.SerialSeconds, len(selected))
+		if len(estimate.Unknown) > 0 {
+			// Unknown recipes count as 0s, so the estimate is a lower bound.
+			fmt.Printf("unknown, counted as 0s: %s\n", strings.Join(estimate.Unknown, ", "))
+		}
+
+		return nil
+	},
+}
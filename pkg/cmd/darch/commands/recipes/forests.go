@@ -0,0 +1,61 @@
+package recipes
+
+import (
+	"sort"
+
+	"github.com/disiqueira/gotree"
+	"github.com/godarch/darch/pkg/recipes"
+	"github.com/urfave/cli"
+)
+
+var forestsCommand = cli.Command{
+	Name:  "forests",
+	Usage: "list the recipes as separate trees, each rooted at a recipe whose parent is external or missing",
+	Action: func(clicontext *cli.Context) error {
+		rs, err := parseAllRecipes(clicontext)
+		loadErr, ok := err.(*recipes.LoadError)
+		if !ok && err != nil {
+			return err
+		}
+
+		for _, forest := range buildForests(rs, loadErr) {
+			gotree.PrintTree(forest)
+		}
+
+		return nil
+	},
+}
+
+// buildForests Builds a tree for every recipe that inherits an external
+// image or a recipe that doesn't exist, ordered by the root's name. The
+// recipes inheriting one that loadErr skipped aren't roots, as their parent
+// exists. loadErr may be nil.
+func buildForests(rs map[string]recipes.Recipe, loadErr *recipes.LoadError) []gotree.GTStructure {
+	children := recipes.DirectChildren(rs)
+
+	roots := make([]string, 0)
+	for _, r := range rs {
+		if _, ok := rs[r.Inherits]; r.InheritsExternal || (!ok && !loadErr.Skipped(r.Inherits)) {
+			roots = append(roots, r.Name)
+		}
+	}
+	sort.Strings(roots)
+
+	var build func(name string) gotree.GTStructure
+	build = func(name string) gotree.GTStructure {
+		var node gotree.GTStructure
+		node.Name = name
+		for _, child := range children[name] {
+			node.Items = append(node.Items, build(child))
+		}
+		return node
+	}
+
+	result := make([]gotree.GTStructure, 0, len(roots))
+	for _, root := range roots {
+		var rootNode gotree.GTStructure
+		rootNode.Items = []gotree.GTStructure{build(root)}
+		result = append(result, rootNode)
+	}
+	return result
+}
@@ -0,0 +1,48 @@
+package recipes
+
+import (
+	"testing"
+
+	"github.com/godarch/darch/pkg/recipes"
+)
+
+func TestBuildForests(t *testing.T) {
+	rs := map[string]recipes.Recipe{
+		"base":   {Name: "base", Inherits: "archlinux/base", InheritsExternal: true},
+		"common": {Name: "common", Inherits: "base"},
+		"tools":  {Name: "tools", Inherits: "ubuntu:20.04", InheritsExternal: true},
+		"orphan": {Name: "orphan", Inherits: "missing"},
+		"a":      {Name: "a", Inherits: "b"},
+		"b":      {Name: "b", Inherits: "a"},
+	}
+
+	forests := buildForests(rs, nil)
+	if len(forests) != 3 {
+		t.Fatalf("expected 3 forests, got %d", len(forests))
+	}
+
+	for i, expected := range []string{"base", "orphan", "tools"} {
+		if forests[i].Items[0].Name != expected {
+			t.Fatalf("expected forest %d to be rooted at %s, got %s", i, expected, forests[i].Items[0].Name)
+		}
+	}
+
+	if len(forests[0].Items[0].Items) != 1 || forests[0].Items[0].Items[0].Name != "common" {
+		t.Fatalf("unexpected children %v", forests[0].Items[0].Items)
+	}
+}
+
+func TestBuildForestsSkipped(t *testing.T) {
+	rs := map[string]recipes.Recipe{
+		"base":    {Name: "base", Inherits: "archlinux/base", InheritsExternal: true},
+		"desktop": {Name: "desktop", Inherits: "broken"},
+	}
+	loadErr := &recipes.LoadError{Problems: []recipes.Problem{
+		{Kind: recipes.ProblemInvalid, Recipes: []string{"broken"}},
+	}}
+
+	forests := buildForests(rs, loadErr)
+	if len(forests) != 1 || forests[0].Items[0].Name != "base" {
+		t.Fatalf("expected only base to be a root, got %v", forests)
+	}
+}
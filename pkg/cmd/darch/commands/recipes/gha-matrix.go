@@ -0,0 +1,99 @@
+package recipes
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/godarch/darch/pkg/recipes"
+	"github.com/urfave/cli"
+)
+
+// ghaMatrixEntry A recipe to build, and the stage it is built in.
+type ghaMatrixEntry struct {
+	Recipe string `json:"recipe"`
+	Stage  int    `json:"stage"`
+}
+
+// ghaStage The recipes to build in one stage, as a GitHub Actions matrix.
+type ghaStage struct {
+	Recipe []string `json:"recipe"`
+}
+
+// ghaMatrix The output of gha-matrix. Include has every recipe, for a
+// single job that builds the stages in order. Stages has a matrix for each
+// stage, for a job per stage, where each job needs the one before it.
+type ghaMatrix struct {
+	Include []ghaMatrixEntry `json:"include"`
+	Stages  []ghaStage       `json:"stages"`
+}
+
+var ghaMatrixCommand = cli.Command{
+	Name:      "gha-matrix",
+	Usage:     "print a GitHub Actions matrix of a recipe and its descendants, grouped into stages",
+	ArgsUsage: "<recipe>",
+	Description: `Prints a single line of JSON like:
+
+   {"include":[{"recipe":"web","stage":0},{"recipe":"web-api","stage":1}],"stages":[{"recipe":["web"]},{"recipe":["web-api"]}]}
+
+   "include" can be used as the matrix of a single job, building in stage
+   order. Each of "stages" can be used as the matrix of its own job, which
+   needs the job for the previous stage, with fromJSON(...).stages[N].`,
+	Flags: []cli.Flag{
+		cli.BoolFlag{
+			Name:  "all",
+			Usage: "include all recipes, rather than a recipe and its descendants",
+		},
+	},
+	Action: func(clicontext *cli.Context) error {
+		var (
+			recipeName = clicontext.Args().First()
+			all        = clicontext.Bool("all")
+		)
+
+		if len(recipeName) == 0 && !all {
+			return fmt.Errorf("You must provide a recipe name or --all")
+		}
+
+		rs, err := getAllRecipes(clicontext)
+		if err != nil {
+			return err
+		}
+
+		waves, err := recipes.BuildWaves(rs)
+		if err != nil {
+			return err
+		}
+
+		if !all {
+			current, ok := rs[recipeName]
+			if !ok {
+				return fmt.Errorf("Recipe %s doesn't exist", recipeName)
+			}
+			selected := map[string]bool{current.Name: true}
+			for _, descendant := range recipes.Descendants(current, rs, -1) {
+				selected[descendant] = true
+			}
+			waves = filterWaves(waves, selected)
+		}
+
+		matrix := ghaMatrix{
+			Include: make([]ghaMatrixEntry, 0),
+			Stages:  make([]ghaStage, 0, len(waves)),
+		}
+		for stage, wave := range waves {
+			for _, name := range wave {
+				matrix.Include = append(matrix.Include, ghaMatrixEntry{Recipe: name, Stage: stage})
+			}
+			matrix.Stages = append(matrix.Stages, ghaStage{Recipe: wave})
+		}
+
+		jsonData, err := json.Marshal(matrix)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(string(jsonData))
+
+		return nil
+	},
+}
@@ -0,0 +1,205 @@
+package recipes
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/godarch/darch/pkg/recipes"
+	"github.com/godarch/darch/pkg/utils"
+	"github.com/urfave/cli"
+)
+
+var graphFormats = []string{"plantuml", "dot"}
+
+var graphCommand = cli.Command{
+	Name:  "graph",
+	Usage: "output the recipe inheritance graph",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "format",
+			Usage: "the output format (" + strings.Join(graphFormats, ", ") + ")",
+			Value: "plantuml",
+		},
+	},
+	Action: func(clicontext *cli.Context) error {
+		var (
+			format = clicontext.String("format")
+		)
+
+		rs, err := getAllRecipes(clicontext)
+		if err != nil {
+			return err
+		}
+
+		output, err := renderGraph(format, newRecipeGraph(rs))
+		if err != nil {
+			return err
+		}
+
+		fmt.Print(output)
+
+		return nil
+	},
+}
+
+// graphEdge An edge from a parent to the recipe inheriting it.
+type graphEdge struct {
+	Parent         string
+	ParentExternal bool
+	Child          string
+}
+
+// recipeGraph The recipes and external images to render, and the edges between them.
+type recipeGraph struct {
+	Recipes   []string
+	Externals []string
+	Edges     []graphEdge
+}
+
+// newRecipeGraph Builds a graph of the given recipes. Edges to internal
+// parents are only included when the parent is also in rs.
+func newRecipeGraph(rs map[string]recipes.Recipe) recipeGraph {
+	g := recipeGraph{
+		Recipes:   make([]string, 0),
+		Externals: make([]string, 0),
+		Edges:     make([]graphEdge, 0),
+	}
+
+	for _, r := range rs {
+		g.Recipes = append(g.Recipes, r.Name)
+	}
+	sort.Strings(g.Recipes)
+
+	for _, name := range g.Recipes {
+		r := rs[name]
+		if r.InheritsExternal {
+			g.Externals = append(g.Externals, r.Inherits)
+			g.Edges = append(g.Edges, graphEdge{Parent: r.Inherits, ParentExternal: true, Child: r.Name})
+		} else if _, ok := rs[r.Inherits]; ok {
+			g.Edges = append(g.Edges, graphEdge{Parent: r.Inherits, Child: r.Name})
+		}
+	}
+	g.Externals = utils.RemoveDuplicates(g.Externals)
+	sort.Strings(g.Externals)
+
+	return g
+}
+
+// withoutExternalOf Removes the edge from the external image the given recipe inherits.
+func (g recipeGraph) withoutExternalOf(recipeName string) recipeGraph {
+	edges := make([]graphEdge, 0)
+	externals := make([]string, 0)
+	for _, edge := range g.Edges {
+		if edge.ParentExternal && edge.Child == recipeName {
+			continue
+		}
+		edges = append(edges, edge)
+		if edge.ParentExternal {
+			externals = append(externals, edge.Parent)
+		}
+	}
+	g.Edges = edges
+	g.Externals = utils.RemoveDuplicates(externals)
+	sort.Strings(g.Externals)
+	return g
+}
+
+// renderGraph Renders the given graph in one of the supported graphFormats.
+func renderGraph(format string, g recipeGraph) (string, error) {
+	switch format {
+	case "plantuml":
+		return renderPlantUML(g), nil
+	case "dot":
+		return renderDot(g), nil
+	}
+	return "", fmt.Errorf("unknown format %s, must be one of %v", format, graphFormats)
+}
+
+var invalidIdentifierChars = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// sanitizeIdentifier Converts a recipe or image name into something usable as an identifier.
+func sanitizeIdentifier(name string) string {
+	return invalidIdentifierChars.ReplaceAllString(name, "_")
+}
+
+// plantUMLLabel Quotes a name for use as a PlantUML class label.
+func plantUMLLabel(name string) string {
+	return "\"" + strings.Replace(name, "\"", "\\\"", -1) + "\""
+}
+
+func renderPlantUML(g recipeGraph) string {
+	// Sanitizing can map different names to the same identifier, such as
+	// base-common and base_common, so later ones are numbered.
+	used := make(map[string]bool, 0)
+	unique := func(identifier string) string {
+		result := identifier
+		for i := 2; used[result]; i++ {
+			result = fmt.Sprintf("%s_%d", identifier, i)
+		}
+		used[result] = true
+		return result
+	}
+	// External images get a prefix so they can't collide with recipe names.
+	externalIdentifiers := make(map[string]string, len(g.Externals))
+	for _, externalImage := range g.Externals {
+		externalIdentifiers[externalImage] = unique("external_" + sanitizeIdentifier(externalImage))
+	}
+	recipeIdentifiers := make(map[string]string, len(g.Recipes))
+	for _, name := range g.Recipes {
+		recipeIdentifiers[name] = unique(sanitizeIdentifier(name))
+	}
+	parentIdentifier := func(edge graphEdge) string {
+		if edge.ParentExternal {
+			return externalIdentifiers[edge.Parent]
+		}
+		return recipeIdentifiers[edge.Parent]
+	}
+
+	var buffer bytes.Buffer
+	buffer.WriteString("@startuml\n")
+	for _, externalImage := range g.Externals {
+		buffer.WriteString(fmt.Sprintf("class %s as %s <<external>>\n", plantUMLLabel(externalImage), externalIdentifiers[externalImage]))
+	}
+	for _, name := range g.Recipes {
+		buffer.WriteString(fmt.Sprintf("class %s as %s\n", plantUMLLabel(name), recipeIdentifiers[name]))
+	}
+	for _, edge := range g.Edges {
+		buffer.WriteString(fmt.Sprintf("%s --|> %s\n", recipeIdentifiers[edge.Child], parentIdentifier(edge)))
+	}
+	buffer.WriteString("@enduml\n")
+
+	return buffer.String()
+}
+
+// dotID Quotes a name for use as a DOT identifier.
+func dotID(name string) string {
+	return "\"" + strings.Replace(strings.Replace(name, "\\", "\\\\", -1), "\"", "\\\"", -1) + "\""
+}
+
+func renderDot(g recipeGraph) string {
+	// External images get a prefix so they can't collide with recipe names.
+	parentID := func(edge graphEdge) string {
+		if edge.ParentExternal {
+			return dotID("external:" + edge.Parent)
+		}
+		return dotID(edge.Parent)
+	}
+
+	var buffer bytes.Buffer
+	buffer.WriteString("digraph darch {\n")
+	for _, externalImage := range g.Externals {
+		buffer.WriteString(fmt.Sprintf("\t%s [label=%s, shape=box];\n", dotID("external:"+externalImage), dotID(externalImage)))
+	}
+	for _, name := range g.Recipes {
+		buffer.WriteString(fmt.Sprintf("\t%s [shape=ellipse];\n", dotID(name)))
+	}
+	for _, edge := range g.Edges {
+		buffer.WriteString(fmt.Sprintf("\t%s -> %s;\n", parentID(edge), dotID(edge.Child)))
+	}
+	buffer.WriteString("}\n")
+
+	return buffer.String()
+}
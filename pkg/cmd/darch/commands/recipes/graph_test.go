@@ -0,0 +1,77 @@
+package recipes
+
+import (
+	"testing"
+
+	"github.com/godarch/darch/pkg/recipes"
+)
+
+func TestRenderPlantUML(t *testing.T) {
+	rs := map[string]recipes.Recipe{
+		"base":        {Name: "base", Inherits: "archlinux/base", InheritsExternal: true},
+		"base-common": {Name: "base-common", Inherits: "base"},
+		"team.web":    {Name: "team.web", Inherits: "base-common"},
+	}
+
+	expected := "@startuml\n" +
+		"class \"archlinux/base\" as external_archlinux_base <<external>>\n" +
+		"class \"base\" as base\n" +
+		"class \"base-common\" as base_common\n" +
+		"class \"team.web\" as team_web\n" +
+		"base --|> external_archlinux_base\n" +
+		"base_common --|> base\n" +
+		"team_web --|> base_common\n" +
+		"@enduml\n"
+
+	if output := renderPlantUML(newRecipeGraph(rs)); output != expected {
+		t.Fatalf("expected\n%s\ngot\n%s", expected, output)
+	}
+}
+
+func TestRenderPlantUMLCollisions(t *testing.T) {
+	rs := map[string]recipes.Recipe{
+		"base-common": {Name: "base-common", Inherits: "archlinux/base", InheritsExternal: true},
+		"base.common": {Name: "base.common", Inherits: "base-common"},
+		"base_common": {Name: "base_common", Inherits: "base.common"},
+		"external_x":  {Name: "external_x", Inherits: "x", InheritsExternal: true},
+		"say \"hi\"":  {Name: "say \"hi\"", Inherits: "base_common"},
+	}
+
+	expected := "@startuml\n" +
+		"class \"archlinux/base\" as external_archlinux_base <<external>>\n" +
+		"class \"x\" as external_x <<external>>\n" +
+		"class \"base-common\" as base_common\n" +
+		"class \"base.common\" as base_common_2\n" +
+		"class \"base_common\" as base_common_3\n" +
+		"class \"external_x\" as external_x_2\n" +
+		"class \"say \\\"hi\\\"\" as say__hi_\n" +
+		"base_common --|> external_archlinux_base\n" +
+		"base_common_2 --|> base_common\n" +
+		"base_common_3 --|> base_common_2\n" +
+		"external_x_2 --|> external_x\n" +
+		"say__hi_ --|> base_common_3\n" +
+		"@enduml\n"
+
+	if output := renderPlantUML(newRecipeGraph(rs)); output != expected {
+		t.Fatalf("expected\n%s\ngot\n%s", expected, output)
+	}
+}
+
+func TestRenderDot(t *testing.T) {
+	rs := map[string]recipes.Recipe{
+		"base":     {Name: "base", Inherits: "archlinux/base", InheritsExternal: true},
+		"team\"db": {Name: "team\"db", Inherits: "base"},
+	}
+
+	expected := "digraph darch {\n" +
+		"\t\"external:archlinux/base\" [label=\"archlinux/base\", shape=box];\n" +
+		"\t\"base\" [shape=ellipse];\n" +
+		"\t\"team\\\"db\" [shape=ellipse];\n" +
+		"\t\"external:archlinux/base\" -> \"base\";\n" +
+		"\t\"base\" -> \"team\\\"db\";\n" +
+		"}\n"
+
+	if output := renderDot(newRecipeGraph(rs)); output != expected {
+		t.Fatalf("expected\n%s\ngot\n%s", expected, output)
+	}
+}
@@ -0,0 +1,92 @@
+package recipes
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/godarch/darch/pkg/cmd/darch/commands"
+	"github.com/godarch/darch/pkg/darchrc"
+	"github.com/urfave/cli"
+)
+
+// TestMain Runs the tests without the developer's ~/.darchrc and DARCH_*
+// environment, which would otherwise change the global flags, for example
+// loading the recipes from a URL.
+func TestMain(m *testing.M) {
+	home, err := ioutil.TempDir("", "darch-home")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error creating home directory %v\n", err)
+		os.Exit(1)
+	}
+	os.Setenv("HOME", home)
+
+	commands.LoadSettings = func() darchrc.Settings {
+		return darchrc.Settings{}
+	}
+	commands.LookupEnv = func(string) (string, bool) {
+		return "", false
+	}
+
+	code := m.Run()
+	os.RemoveAll(home)
+	os.Exit(code)
+}
+
+// testContext Returns the context a subcommand's action is run with, for
+// the recipes in recipesDir, parsing args with the subcommand's flags. Only
+// the first name of each flag can be used, as aliases are resolved by cli
+// when running a command.
+func testContext(t *testing.T, command cli.Command, recipesDir string, args ...string) *cli.Context {
+	return testGlobalContext(t, command, []string{"--recipes-dir", recipesDir}, args...)
+}
+
+// testGlobalContext Returns the context a subcommand's action is run with,
+// like testContext, parsing globalArgs with the recipes command's flags.
+func testGlobalContext(t *testing.T, command cli.Command, globalArgs []string, args ...string) *cli.Context {
+	globalSet := flag.NewFlagSet(Command.Name, flag.ContinueOnError)
+	for _, f := range Command.Flags {
+		f.Apply(globalSet)
+	}
+	if err := globalSet.Parse(globalArgs); err != nil {
+		t.Fatalf("error parsing global flags %v", err)
+	}
+
+	set := flag.NewFlagSet(command.Name, flag.ContinueOnError)
+	for _, f := range command.Flags {
+		f.Apply(set)
+	}
+	if err := set.Parse(args); err != nil {
+		t.Fatalf("error parsing flags %v", err)
+	}
+
+	ctx := cli.NewContext(nil, set, cli.NewContext(nil, globalSet, nil))
+	ctx.Command = command
+	return ctx
+}
+
+// writeTestRecipes Writes a recipe for each name, inheriting the value.
+func writeTestRecipes(t *testing.T, recipesDir string, inherits map[string]string) {
+	configurations := make(map[string]string, len(inherits))
+	for name, parent := range inherits {
+		configurations[name] = `{"inherits": "` + parent + `"}`
+	}
+	writeTestConfigurations(t, recipesDir, configurations)
+}
+
+// writeTestConfigurations Writes a recipe for each name, with the value as
+// its config.json.
+func writeTestConfigurations(t *testing.T, recipesDir string, configurations map[string]string) {
+	for name, configuration := range configurations {
+		if err := os.MkdirAll(path.Join(recipesDir, name), 0755); err != nil {
+			t.Fatalf("error creating recipe directory %v", err)
+		}
+		err := ioutil.WriteFile(path.Join(recipesDir, name, "config.json"), []byte(configuration), 0644)
+		if err != nil {
+			t.Fatalf("error writing recipe configuration %v", err)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package recipes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/urfave/cli"
+)
+
+// inspectOutput The fields of a recipe printed by inspect.
+type inspectOutput struct {
+	Name             string            `json:"name"`
+	Inherits         string            `json:"inherits"`
+	InheritsExternal bool              `json:"inheritsExternal"`
+	Directory        string            `json:"directory"`
+	BuildArgs        map[string]string `json:"buildArgs"`
+	BuildSeconds     int               `json:"buildSeconds"`
+}
+
+var inspectCommand = cli.Command{
+	Name:      "inspect",
+	Usage:     "print the configuration of a recipe",
+	ArgsUsage: "<recipe>",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "format, o",
+			Usage: "the output format (text, json), json is printed to stdout for piping into other tools",
+			Value: "text",
+		},
+	},
+	Action: func(clicontext *cli.Context) error {
+		return runInspect(clicontext, os.Stdout)
+	},
+}
+
+// runInspect Writes the configuration of the recipe named by the first
+// argument to w.
+func runInspect(clicontext *cli.Context, w io.Writer) error {
+	var (
+		recipeName = clicontext.Args().First()
+		format     = clicontext.String("format")
+	)
+
+	if format != "text" && format != "json" {
+		return fmt.Errorf("unknown format %s, must be text or json", format)
+	}
+
+	if len(recipeName) == 0 {
+		return fmt.Errorf("You must provide a recipe name")
+	}
+
+	// Only the recipe itself needs to be valid, so problems loading
+	// other recipes are ignored.
+	rs, err := parseAllRecipes(clicontext)
+	if err = checkLoaded(err, recipeName); err != nil {
+		return err
+	}
+
+	current, ok := rs[recipeName]
+	if !ok {
+		return fmt.Errorf("Recipe %s doesn't exist", recipeName)
+	}
+
+	output := inspectOutput{
+		Name:             current.Name,
+		Inherits:         current.Inherits,
+		InheritsExternal: current.InheritsExternal,
+		Directory:        current.RecipeDir,
+		BuildArgs:        current.BuildArgs,
+		BuildSeconds:     current.BuildSeconds,
+	}
+
+	if format == "json" {
+		jsonData, err := json.MarshalIndent(output, "", "    ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, string(jsonData))
+		return nil
+	}
+
+	fmt.Fprint(w, renderInspect(output))
+
+	return nil
+}
+
+// renderInspect Renders the fields as key/value lines, with the values aligned.
+func renderInspect(output inspectOutput) string {
+	buildArgs := make([]string, 0, len(output.BuildArgs))
+	for key, value := range output.BuildArgs {
+		buildArgs = append(buildArgs, key+"="+value)
+	}
+	sort.Strings(buildArgs)
+
+	fields := [][2]string{
+		{"Name", output.Name},
+		{"Inherits", output.Inherits},
+		{"InheritsExternal", strconv.FormatBool(output.InheritsExternal)},
+		{"Directory", output.Directory},
+		{"BuildArgs", strings.Join(buildArgs, " ")},
+		{"BuildSeconds", strconv.Itoa(output.BuildSeconds)},
+	}
+
+	width := 0
+	for _, field := range fields {
+		if len(field[0]) > width {
+			width = len(field[0])
+		}
+	}
+
+	var buffer bytes.Buffer
+	for _, field := range fields {
+		buffer.WriteString(strings.TrimSpace(fmt.Sprintf("%-*s %s", width+1, field[0]+":", field[1])) + "\n")
+	}
+
+	return buffer.String()
+}
@@ -0,0 +1,98 @@
+package recipes
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/godarch/darch/pkg/utils"
+)
+
+func TestRenderInspect(t *testing.T) {
+	output := inspectOutput{
+		Name:             "web",
+		Inherits:         "base",
+		InheritsExternal: false,
+		Directory:        "/recipes/web",
+		BuildArgs:        map[string]string{"VERSION": "2", "PORT": "80"},
+		BuildSeconds:     90,
+	}
+
+	expected := "Name:             web\n" +
+		"Inherits:         base\n" +
+		"InheritsExternal: false\n" +
+		"Directory:        /recipes/web\n" +
+		"BuildArgs:        PORT=80 VERSION=2\n" +
+		"BuildSeconds:     90\n"
+
+	if rendered := renderInspect(output); rendered != expected {
+		t.Fatalf("expected\n%s\ngot\n%s", expected, rendered)
+	}
+}
+
+func TestRunInspect(t *testing.T) {
+	recipesDir := path.Join(os.TempDir(), utils.NewID())
+	defer os.RemoveAll(recipesDir)
+
+	writeTestRecipes(t, recipesDir, map[string]string{
+		"base": "external:archlinux/base",
+	})
+
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"base"}, "Name:             base\n" +
+			"Inherits:         archlinux/base\n" +
+			"InheritsExternal: true\n" +
+			"Directory:        " + path.Join(recipesDir, "base") + "\n" +
+			"BuildArgs:\n" +
+			"BuildSeconds:     0\n"},
+		{[]string{"--format", "json", "base"}, "{\n" +
+			"    \"name\": \"base\",\n" +
+			"    \"inherits\": \"archlinux/base\",\n" +
+			"    \"inheritsExternal\": true,\n" +
+			"    \"directory\": \"" + path.Join(recipesDir, "base") + "\",\n" +
+			"    \"buildArgs\": {},\n" +
+			"    \"buildSeconds\": 0\n" +
+			"}\n"},
+	}
+
+	for _, test := range tests {
+		var buffer bytes.Buffer
+		if err := runInspect(testContext(t, inspectCommand, recipesDir, test.args...), &buffer); err != nil {
+			t.Fatalf("error running inspect %v", err)
+		}
+		if buffer.String() != test.expected {
+			t.Fatalf("expected %q for %v, got %q", test.expected, test.args, buffer.String())
+		}
+	}
+}
+
+func TestRunInspectMalformed(t *testing.T) {
+	recipesDir := path.Join(os.TempDir(), utils.NewID())
+	defer os.RemoveAll(recipesDir)
+
+	writeTestConfigurations(t, recipesDir, map[string]string{
+		"base":   `{"inherits": "external:archlinux/base"}`,
+		"broken": `{"inherits": `,
+	})
+
+	var buffer bytes.Buffer
+	if err := runInspect(testContext(t, inspectCommand, recipesDir, "broken"), &buffer); err == nil {
+		t.Fatal("expected an error for a malformed configuration")
+	}
+	if buffer.Len() != 0 {
+		t.Fatalf("expected nothing to be printed, got %q", buffer.String())
+	}
+
+	// A malformed configuration in another recipe doesn't matter.
+	if err := runInspect(testContext(t, inspectCommand, recipesDir, "base"), &buffer); err != nil {
+		t.Fatalf("error running inspect %v", err)
+	}
+	if !strings.HasPrefix(buffer.String(), "Name:             base\n") {
+		t.Fatalf("unexpected output %q", buffer.String())
+	}
+}
@@ -0,0 +1,40 @@
+package recipes
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/godarch/darch/pkg/recipes"
+	"github.com/urfave/cli"
+)
+
+var leavesCommand = cli.Command{
+	Name:  "leaves",
+	Usage: "list the recipes that no other recipe inherits",
+	Flags: []cli.Flag{
+		cli.BoolFlag{
+			Name: "reverse",
+		},
+	},
+	Action: func(clicontext *cli.Context) error {
+		var (
+			reverse = clicontext.Bool("reverse")
+		)
+
+		rs, err := getAllRecipes(clicontext)
+		if err != nil {
+			return err
+		}
+
+		leaves := recipes.Leaves(rs)
+		if reverse {
+			sort.Sort(sort.Reverse(sort.StringSlice(leaves)))
+		}
+
+		for _, name := range leaves {
+			fmt.Println(name)
+		}
+
+		return nil
+	},
+}
@@ -0,0 +1,117 @@
+package recipes
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/godarch/darch/pkg/recipes"
+	"github.com/urfave/cli"
+)
+
+var lineageDiffCommand = cli.Command{
+	Name:      "lineage-diff",
+	Usage:     "list the recipes in a recipe's chain that changed since a git ref",
+	ArgsUsage: "<recipe>",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "since",
+			Usage: "the git ref to compare the recipes directory against",
+		},
+	},
+	Action: func(clicontext *cli.Context) error {
+		var (
+			recipeName = clicontext.Args().First()
+			since      = clicontext.String("since")
+			recipesDir = getRecipesDir(clicontext)
+		)
+
+		if len(recipeName) == 0 {
+			return fmt.Errorf("You must provide a recipe name")
+		}
+
+		if len(since) == 0 {
+			return fmt.Errorf("You must provide --since")
+		}
+
+		if err := requireRecipesDir(clicontext); err != nil {
+			return err
+		}
+
+		rs, err := getAllRecipes(clicontext)
+		if err != nil {
+			return err
+		}
+
+		current, ok := rs[recipeName]
+		if !ok {
+			return fmt.Errorf("Recipe %s doesn't exist", recipeName)
+		}
+
+		chain, err := recipes.ResolveChain(current, rs)
+		if err != nil {
+			return err
+		}
+
+		files, err := gitChangedFiles(recipesDir, since)
+		if err != nil {
+			return err
+		}
+
+		changed := recipesForFiles(files)
+		for _, r := range chain {
+			if changed[r.Name] {
+				fmt.Println(r.Name)
+			}
+		}
+
+		return nil
+	},
+}
+
+// gitChangedFiles Returns the files in dir, relative to it, that differ from
+// the given ref, including untracked files.
+func gitChangedFiles(dir string, ref string) ([]string, error) {
+	diff, err := runGit(dir, "diff", "--name-only", "--relative", ref, "--")
+	if err != nil {
+		return nil, err
+	}
+
+	untracked, err := runGit(dir, "ls-files", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(diff, untracked...), nil
+}
+
+func runGit(dir string, args ...string) ([]string, error) {
+	output, err := exec.Command("git", append([]string{"-C", dir}, args...)...).Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+			return nil, fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, err
+	}
+
+	result := make([]string, 0)
+	for _, line := range strings.Split(string(output), "\n") {
+		if len(line) > 0 {
+			result = append(result, line)
+		}
+	}
+	return result, nil
+}
+
+// recipesForFiles Returns the names of the recipes containing the given
+// files, which are relative to the recipes directory.
+func recipesForFiles(files []string) map[string]bool {
+	result := make(map[string]bool, 0)
+	for _, file := range files {
+		parts := strings.SplitN(file, "/", 2)
+		if len(parts) == 2 {
+			result[parts[0]] = true
+		}
+	}
+	return result
+}
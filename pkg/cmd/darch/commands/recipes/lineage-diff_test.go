@@ -0,0 +1,15 @@
+package recipes
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRecipesForFiles(t *testing.T) {
+	changed := recipesForFiles([]string{"base/config.json", "base/script", "web/files/index.html", "README.md"})
+
+	expected := map[string]bool{"base": true, "web": true}
+	if !reflect.DeepEqual(changed, expected) {
+		t.Fatalf("expected %v, got %v", expected, changed)
+	}
+}
@@ -2,6 +2,9 @@ package recipes
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"sort"
 
 	"github.com/godarch/darch/pkg/recipes"
 	"github.com/urfave/cli"
@@ -10,16 +13,57 @@ import (
 var listCommand = cli.Command{
 	Name:  "list",
 	Usage: "list all recipes",
+	Flags: []cli.Flag{
+		cli.BoolFlag{
+			Name:  "external",
+			Usage: "list the distinct external images the recipes are built on instead",
+		},
+		cli.BoolFlag{
+			Name:  "dedupe-externals",
+			Usage: "with --external, group equivalent external images, such as ubuntu and docker.io/library/ubuntu:latest, together",
+		},
+	},
 	Action: func(clicontext *cli.Context) error {
-		rs, err := recipes.GetAllRecipes(getRecipesDir(clicontext))
-		if err != nil {
-			return err
-		}
+		return runList(clicontext, os.Stdout)
+	},
+}
+
+// runList Writes the names of the recipes, or of the external images they
+// are built on, to w.
+func runList(clicontext *cli.Context, w io.Writer) error {
+	var (
+		external = clicontext.Bool("external")
+		dedupe   = clicontext.Bool("dedupe-externals")
+	)
+
+	if dedupe && !external {
+		return fmt.Errorf("--dedupe-externals can only be used with --external")
+	}
 
+	rs, err := getAllRecipes(clicontext)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(rs))
+	if external {
+		externalBases := recipes.ExternalBases(rs)
+		if dedupe {
+			externalBases = dedupeExternalBases(externalBases)
+		}
+		for externalImage := range externalBases {
+			names = append(names, externalImage)
+		}
+	} else {
 		for _, r := range rs {
-			fmt.Println(r.Name)
+			names = append(names, r.Name)
 		}
+	}
+	sort.Strings(names)
 
-		return nil
-	},
+	for _, name := range names {
+		fmt.Fprintln(w, name)
+	}
+
+	return nil
 }
@@ -0,0 +1,94 @@
+package recipes
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/godarch/darch/pkg/recipes"
+	"github.com/urfave/cli"
+)
+
+var maxWidthCommand = cli.Command{
+	Name:      "max-width",
+	Usage:     "print the maximum number of recipes that can be built in parallel",
+	ArgsUsage: "<recipe>",
+	Flags: []cli.Flag{
+		cli.BoolFlag{
+			Name:  "all",
+			Usage: "use all recipes, rather than a recipe and its descendants",
+		},
+		cli.BoolFlag{
+			Name:  "show-wave",
+			Usage: "also print the first wave with the maximum width, numbered from 0 like the waves command",
+		},
+	},
+	Action: func(clicontext *cli.Context) error {
+		var (
+			recipeName = clicontext.Args().First()
+			all        = clicontext.Bool("all")
+			showWave   = clicontext.Bool("show-wave")
+		)
+
+		if len(recipeName) == 0 && !all {
+			return fmt.Errorf("You must provide a recipe name or --all")
+		}
+
+		rs, err := getAllRecipes(clicontext)
+		if err != nil {
+			return err
+		}
+
+		waves, err := recipes.BuildWaves(rs)
+		if err != nil {
+			return err
+		}
+
+		if !all {
+			current, ok := rs[recipeName]
+			if !ok {
+				return fmt.Errorf("Recipe %s doesn't exist", recipeName)
+			}
+			selected := map[string]bool{current.Name: true}
+			for _, descendant := range recipes.Descendants(current, rs, -1) {
+				selected[descendant] = true
+			}
+			waves = filterWaves(waves, selected)
+		}
+
+		if len(waves) == 0 {
+			fmt.Println(0)
+			return nil
+		}
+
+		widest := 0
+		for i, wave := range waves {
+			if len(wave) > len(waves[widest]) {
+				widest = i
+			}
+		}
+
+		fmt.Println(len(waves[widest]))
+		if showWave {
+			fmt.Printf("wave %d: %s\n", widest, strings.Join(waves[widest], " "))
+		}
+
+		return nil
+	},
+}
+
+// filterWaves Removes the recipes that aren't selected from the waves, dropping any waves left empty.
+func filterWaves(waves [][]string, selected map[string]bool) [][]string {
+	result := make([][]string, 0)
+	for _, wave := range waves {
+		filtered := make([]string, 0)
+		for _, name := range wave {
+			if selected[name] {
+				filtered = append(filtered, name)
+			}
+		}
+		if len(filtered) > 0 {
+			result = append(result, filtered)
+		}
+	}
+	return result
+}
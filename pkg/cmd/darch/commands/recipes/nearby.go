@@ -0,0 +1,51 @@
+package recipes
+
+import (
+	"fmt"
+
+	"github.com/godarch/darch/pkg/recipes"
+	"github.com/urfave/cli"
+)
+
+var nearbyCommand = cli.Command{
+	Name:      "nearby",
+	Usage:     "list the recipes within a number of inheritance hops of a recipe, counting hops to parents and children alike",
+	ArgsUsage: "<recipe>",
+	Flags: []cli.Flag{
+		cli.IntFlag{
+			Name:  "radius",
+			Usage: "the number of hops in either direction, so siblings are 2 hops away",
+			Value: 1,
+		},
+	},
+	Action: func(clicontext *cli.Context) error {
+		var (
+			recipeName = clicontext.Args().First()
+			radius     = clicontext.Int("radius")
+		)
+
+		if len(recipeName) == 0 {
+			return fmt.Errorf("You must provide a recipe name")
+		}
+
+		if radius < 0 {
+			return fmt.Errorf("--radius must not be negative")
+		}
+
+		rs, err := getAllRecipes(clicontext)
+		if err != nil {
+			return err
+		}
+
+		current, ok := rs[recipeName]
+		if !ok {
+			return fmt.Errorf("Recipe %s doesn't exist", recipeName)
+		}
+
+		for _, name := range recipes.Nearby(current, rs, radius) {
+			fmt.Println(name)
+		}
+
+		return nil
+	},
+}
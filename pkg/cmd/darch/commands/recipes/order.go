@@ -0,0 +1,55 @@
+package recipes
+
+import (
+	"fmt"
+
+	"github.com/godarch/darch/pkg/recipes"
+	"github.com/godarch/darch/pkg/utils"
+	"github.com/urfave/cli"
+)
+
+var orderCommand = cli.Command{
+	Name:  "order",
+	Usage: "list all recipes in the order they need to be built",
+	Flags: []cli.Flag{
+		cli.BoolFlag{
+			Name:  "only-leaves",
+			Usage: "only print the recipes that nothing inherits, still in build order",
+		},
+		cli.BoolFlag{
+			Name:  "reverse",
+			Usage: "print the recipes in teardown order, every recipe before the recipe it inherits",
+		},
+	},
+	Action: func(clicontext *cli.Context) error {
+		var (
+			onlyLeaves = clicontext.Bool("only-leaves")
+			reverse    = clicontext.Bool("reverse")
+		)
+
+		rs, err := getAllRecipes(clicontext)
+		if err != nil {
+			return err
+		}
+
+		order, err := recipes.BuildOrder(rs)
+		if err != nil {
+			return err
+		}
+
+		if reverse {
+			order = utils.Reverse(order)
+		}
+
+		leaves := recipes.Leaves(rs)
+
+		for _, name := range order {
+			if onlyLeaves && !utils.Contains(leaves, name) {
+				continue
+			}
+			fmt.Println(name)
+		}
+
+		return nil
+	},
+}
@@ -0,0 +1,36 @@
+package recipes
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/godarch/darch/pkg/recipes"
+	"github.com/urfave/cli"
+)
+
+var orphansCommand = cli.Command{
+	Name:  "orphans",
+	Usage: "list the recipes inheriting a recipe that doesn't exist, and the missing parent",
+	Action: func(clicontext *cli.Context) error {
+		rs, err := parseAllRecipes(clicontext)
+		loadErr, ok := err.(*recipes.LoadError)
+		if !ok && err != nil {
+			return err
+		}
+
+		results := make([]string, 0)
+		for _, r := range rs {
+			// A parent that couldn't be loaded exists, so it isn't missing.
+			if _, ok := rs[r.Inherits]; !ok && !r.InheritsExternal && !loadErr.Skipped(r.Inherits) {
+				results = append(results, fmt.Sprintf("%s %s", r.Name, r.Inherits))
+			}
+		}
+		sort.Strings(results)
+
+		for _, result := range results {
+			fmt.Println(result)
+		}
+
+		return nil
+	},
+}
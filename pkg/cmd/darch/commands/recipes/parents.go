@@ -1,8 +1,12 @@
 package recipes
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
-	"log"
+	"io"
+	"os"
+	"sort"
 
 	"github.com/godarch/darch/pkg/recipes"
 	"github.com/godarch/darch/pkg/utils"
@@ -19,51 +23,184 @@ var parentsCommand = cli.Command{
 		cli.BoolFlag{
 			Name: "reverse",
 		},
+		cli.IntFlag{
+			Name:  "max-depth",
+			Usage: "the number of parents to list, counting the external image, 0 lists none",
+		},
+		cli.BoolFlag{
+			Name:  "verbose",
+			Usage: "list the parents from the external image down, with the build args each one introduces or overrides",
+		},
+		cli.StringFlag{
+			Name:  "format",
+			Usage: "the output format of --verbose (text, json)",
+			Value: "text",
+		},
 	},
 	Action: func(clicontext *cli.Context) error {
-		var (
-			recipeName      = clicontext.Args().First()
-			excludeExternal = clicontext.Bool("exclude-external")
-			reverse         = clicontext.Bool("reverse")
-		)
-
-		if len(recipeName) == 0 {
-			return fmt.Errorf("You must provide a recipe name")
-		}
+		return runParents(clicontext, os.Stdout)
+	},
+}
 
-		rs, err := recipes.GetAllRecipes(getRecipesDir(clicontext))
-		if err != nil {
-			return err
-		}
+// runParents Writes the parents of the recipe named by the first argument to w.
+func runParents(clicontext *cli.Context, w io.Writer) error {
+	var (
+		recipeName      = clicontext.Args().First()
+		excludeExternal = clicontext.Bool("exclude-external")
+		reverse         = clicontext.Bool("reverse")
+		maxDepth        = clicontext.Int("max-depth")
+		verbose         = clicontext.Bool("verbose")
+		format          = clicontext.String("format")
+	)
+
+	if maxDepth < 0 {
+		return fmt.Errorf("--max-depth must not be negative")
+	}
+
+	if format != "text" && format != "json" {
+		return fmt.Errorf("unknown format %s, must be text or json", format)
+	}
 
-		current, ok := rs[recipeName]
-		if !ok {
-			return fmt.Errorf("Recipe %s doesn't exist", recipeName)
+	if format != "text" && !verbose {
+		return fmt.Errorf("--format can only be used with --verbose")
+	}
+
+	if verbose && reverse {
+		return fmt.Errorf("--verbose always lists the parents from the external image down, and can't be used with --reverse")
+	}
+
+	if len(recipeName) == 0 {
+		return fmt.Errorf("You must provide a recipe name")
+	}
+
+	rs, err := getAllRecipes(clicontext)
+	if err != nil {
+		return err
+	}
+
+	current, ok := rs[recipeName]
+	if !ok {
+		return fmt.Errorf("Recipe %s doesn't exist", recipeName)
+	}
+
+	chain, err := recipes.ResolveChain(current, rs)
+	if err != nil {
+		return err
+	}
+
+	if verbose {
+		layers := parentLayers(chain)
+		if excludeExternal {
+			layers = layers[1:]
+		}
+		if clicontext.IsSet("max-depth") && len(layers) > maxDepth {
+			layers = layers[len(layers)-maxDepth:]
+		}
+		if format == "json" {
+			jsonData, err := json.MarshalIndent(layers, "", "    ")
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(w, string(jsonData))
+			return nil
 		}
+		fmt.Fprint(w, renderParentLayers(layers))
+		return nil
+	}
 
-		results := make([]string, 0)
+	results := make([]string, 0)
+	for _, parent := range chain[1:] {
+		results = append(results, parent.Name)
+	}
+	if !excludeExternal {
+		results = append(results, chain[len(chain)-1].Inherits)
+	}
 
-		finished := false
-		for finished != true {
-			if current.InheritsExternal {
-				if !excludeExternal {
-					results = append(results, current.Inherits)
-				}
-				finished = true
-			} else {
-				current = rs[current.Inherits]
-				results = append(results, current.Name)
+	if clicontext.IsSet("max-depth") && len(results) > maxDepth {
+		results = results[:maxDepth]
+	}
+
+	if reverse {
+		results = utils.Reverse(results)
+	}
+
+	for _, result := range results {
+		fmt.Fprintln(w, result)
+	}
+
+	return nil
+}
+
+// parentLayer A parent of a recipe, with the build args it introduces or
+// overrides.
+type parentLayer struct {
+	Name     string `json:"name"`
+	External bool   `json:"external"`
+	// BuildArgs The build args set by this parent that aren't set, or are
+	// set to a different value, above it.
+	BuildArgs map[string]string `json:"buildArgs"`
+	// Overrides The values of the overridden build args above this parent.
+	Overrides map[string]string `json:"overrides"`
+}
+
+// parentLayers Returns the parents in a chain, as returned by
+// recipes.ResolveChain, from the external image down.
+func parentLayers(chain []recipes.Recipe) []parentLayer {
+	layers := []parentLayer{{
+		Name:      chain[len(chain)-1].Inherits,
+		External:  true,
+		BuildArgs: map[string]string{},
+		Overrides: map[string]string{},
+	}}
+
+	merged := make(map[string]string, 0)
+	for i := len(chain) - 1; i >= 1; i-- {
+		layer := parentLayer{
+			Name:      chain[i].Name,
+			BuildArgs: make(map[string]string, 0),
+			Overrides: make(map[string]string, 0),
+		}
+		for key, value := range chain[i].BuildArgs {
+			previous, ok := merged[key]
+			if ok && previous == value {
+				continue
+			}
+			if ok {
+				layer.Overrides[key] = previous
 			}
+			layer.BuildArgs[key] = value
+			merged[key] = value
 		}
+		layers = append(layers, layer)
+	}
 
-		if reverse {
-			results = utils.Reverse(results)
+	return layers
+}
+
+// renderParentLayers Renders each parent with its build args indented below
+// it, marking the overridden ones.
+func renderParentLayers(layers []parentLayer) string {
+	var buffer bytes.Buffer
+	for _, layer := range layers {
+		if layer.External {
+			buffer.WriteString(layer.Name + " (external)\n")
+		} else {
+			buffer.WriteString(layer.Name + "\n")
 		}
 
-		for _, result := range results {
-			log.Println(result)
+		keys := make([]string, 0, len(layer.BuildArgs))
+		for key := range layer.BuildArgs {
+			keys = append(keys, key)
 		}
+		sort.Strings(keys)
 
-		return nil
-	},
+		for _, key := range keys {
+			if previous, ok := layer.Overrides[key]; ok {
+				buffer.WriteString(fmt.Sprintf("  %s=%s (overrides %s)\n", key, layer.BuildArgs[key], previous))
+			} else {
+				buffer.WriteString(fmt.Sprintf("  %s=%s\n", key, layer.BuildArgs[key]))
+			}
+		}
+	}
+	return buffer.String()
 }
@@ -0,0 +1,89 @@
+package recipes
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/godarch/darch/pkg/utils"
+)
+
+func TestRunParents(t *testing.T) {
+	recipesDir := path.Join(os.TempDir(), utils.NewID())
+	defer os.RemoveAll(recipesDir)
+
+	writeTestRecipes(t, recipesDir, map[string]string{
+		"base":    "external:archlinux/base",
+		"server":  "base",
+		"web":     "server",
+		"desktop": "base",
+	})
+
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"web"}, "server\nbase\narchlinux/base\n"},
+		{[]string{"--exclude-external", "--reverse", "web"}, "base\nserver\n"},
+		{[]string{"--max-depth", "1", "web"}, "server\n"},
+	}
+
+	for _, test := range tests {
+		var buffer bytes.Buffer
+		if err := runParents(testContext(t, parentsCommand, recipesDir, test.args...), &buffer); err != nil {
+			t.Fatalf("error running parents %v", err)
+		}
+		if buffer.String() != test.expected {
+			t.Fatalf("expected %q for %v, got %q", test.expected, test.args, buffer.String())
+		}
+	}
+}
+
+func TestRunParentsVerbose(t *testing.T) {
+	recipesDir := path.Join(os.TempDir(), utils.NewID())
+	defer os.RemoveAll(recipesDir)
+
+	configurations := map[string]string{
+		"base":   `{"inherits": "external:archlinux/base", "buildArgs": {"NODE_VERSION": "16", "LOCALE": "en_US"}}`,
+		"server": `{"inherits": "base", "buildArgs": {"NODE_VERSION": "18", "LOCALE": "en_US"}}`,
+		"web":    `{"inherits": "server", "buildArgs": {"PORT": "80"}}`,
+	}
+	writeTestConfigurations(t, recipesDir, configurations)
+
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"--verbose", "web"}, "archlinux/base (external)\n" +
+			"base\n" +
+			"  LOCALE=en_US\n" +
+			"  NODE_VERSION=16\n" +
+			"server\n" +
+			"  NODE_VERSION=18 (overrides 16)\n"},
+		{[]string{"--verbose", "--exclude-external", "--max-depth", "1", "web"}, "server\n" +
+			"  NODE_VERSION=18 (overrides 16)\n"},
+		{[]string{"--verbose", "--format", "json", "--max-depth", "1", "web"}, "[\n" +
+			"    {\n" +
+			"        \"name\": \"server\",\n" +
+			"        \"external\": false,\n" +
+			"        \"buildArgs\": {\n" +
+			"            \"NODE_VERSION\": \"18\"\n" +
+			"        },\n" +
+			"        \"overrides\": {\n" +
+			"            \"NODE_VERSION\": \"16\"\n" +
+			"        }\n" +
+			"    }\n" +
+			"]\n"},
+	}
+
+	for _, test := range tests {
+		var buffer bytes.Buffer
+		if err := runParents(testContext(t, parentsCommand, recipesDir, test.args...), &buffer); err != nil {
+			t.Fatalf("error running parents %v", err)
+		}
+		if buffer.String() != test.expected {
+			t.Fatalf("expected %q for %v, got %q", test.expected, test.args, buffer.String())
+		}
+	}
+}
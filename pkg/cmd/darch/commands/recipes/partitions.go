@@ -0,0 +1,45 @@
+package recipes
+
+import (
+	"fmt"
+
+	"github.com/godarch/darch/pkg/recipes"
+	"github.com/urfave/cli"
+)
+
+var partitionsCommand = cli.Command{
+	Name:  "partitions",
+	Usage: "group recipes into sets that can be built independently of each other",
+	Flags: []cli.Flag{
+		cli.BoolFlag{
+			Name:  "join-externals",
+			Usage: "group recipes built on the same external image together",
+		},
+	},
+	Action: func(clicontext *cli.Context) error {
+		var (
+			joinExternals = clicontext.Bool("join-externals")
+		)
+
+		rs, err := getAllRecipes(clicontext)
+		if err != nil {
+			return err
+		}
+
+		partitions, err := recipes.Partitions(rs, joinExternals)
+		if err != nil {
+			return err
+		}
+
+		for i, partition := range partitions {
+			if i > 0 {
+				fmt.Println()
+			}
+			for _, name := range partition {
+				fmt.Println(name)
+			}
+		}
+
+		return nil
+	},
+}
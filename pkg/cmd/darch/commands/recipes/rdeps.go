@@ -0,0 +1,65 @@
+package recipes
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/godarch/darch/pkg/recipes"
+	"github.com/urfave/cli"
+)
+
+var rdepsCommand = cli.Command{
+	Name:  "rdeps",
+	Usage: "print the recipes directly inheriting each recipe",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "output, o",
+			Usage: "the output format (text, json)",
+			Value: "text",
+		},
+	},
+	Action: func(clicontext *cli.Context) error {
+		var (
+			output = clicontext.String("output")
+		)
+
+		if output != "text" && output != "json" {
+			return fmt.Errorf("unknown output %s, must be text or json", output)
+		}
+
+		rs, err := getAllRecipes(clicontext)
+		if err != nil {
+			return err
+		}
+
+		children := recipes.DirectChildren(rs)
+
+		index := make(map[string][]string, len(rs))
+		names := make([]string, 0, len(rs))
+		for name := range rs {
+			index[name] = children[name]
+			if index[name] == nil {
+				index[name] = make([]string, 0)
+			}
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		if output == "json" {
+			jsonData, err := json.MarshalIndent(index, "", "    ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(jsonData))
+			return nil
+		}
+
+		for _, name := range names {
+			fmt.Println(strings.TrimSpace(name + ": " + strings.Join(index[name], " ")))
+		}
+
+		return nil
+	},
+}
@@ -0,0 +1,81 @@
+package recipes
+
+import (
+	"fmt"
+
+	"github.com/godarch/darch/pkg/recipes"
+	"github.com/urfave/cli"
+)
+
+var rebuildSetCommand = cli.Command{
+	Name:  "rebuild-set",
+	Usage: "list the changed recipes and every recipe inheriting from them, in the order they need to be built",
+	Flags: []cli.Flag{
+		cli.StringSliceFlag{
+			Name:  "changed",
+			Usage: "a recipe that changed, can be given more than once",
+		},
+		cli.BoolFlag{
+			Name:  "waves",
+			Usage: "group the recipes into waves that can be built in parallel",
+		},
+	},
+	Action: func(clicontext *cli.Context) error {
+		var (
+			changed = clicontext.StringSlice("changed")
+			waves   = clicontext.Bool("waves")
+		)
+
+		if len(changed) == 0 {
+			return fmt.Errorf("You must provide at least one --changed recipe")
+		}
+
+		rs, err := getAllRecipes(clicontext)
+		if err != nil {
+			return err
+		}
+
+		descendants := recipes.AllDescendants(rs)
+
+		selected := make(map[string]bool, 0)
+		for _, recipeName := range changed {
+			if _, ok := rs[recipeName]; !ok {
+				return fmt.Errorf("Recipe %s doesn't exist", recipeName)
+			}
+			selected[recipeName] = true
+			for _, descendant := range descendants[recipeName] {
+				selected[descendant] = true
+			}
+		}
+
+		if waves {
+			allWaves, err := recipes.BuildWaves(rs)
+			if err != nil {
+				return err
+			}
+			for i, wave := range filterWaves(allWaves, selected) {
+				if i > 0 {
+					fmt.Println()
+				}
+				fmt.Printf("wave %d:\n", i)
+				for _, name := range wave {
+					fmt.Printf("  %s\n", name)
+				}
+			}
+			return nil
+		}
+
+		order, err := recipes.BuildOrder(rs)
+		if err != nil {
+			return err
+		}
+
+		for _, name := range order {
+			if selected[name] {
+				fmt.Println(name)
+			}
+		}
+
+		return nil
+	},
+}
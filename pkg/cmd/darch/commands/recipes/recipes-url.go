@@ -0,0 +1,79 @@
+package recipes
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+
+	"github.com/godarch/darch/pkg/cmd/darch/commands"
+	"github.com/godarch/darch/pkg/recipes"
+	"github.com/urfave/cli"
+)
+
+// recipesURLAuthEnvVar The environment variable with the value of the
+// Authorization header to send when fetching --recipes-url.
+const recipesURLAuthEnvVar = "DARCH_RECIPES_URL_AUTH"
+
+// getRecipesURL Returns the URL of the recipe bundle to load instead of the
+// recipes directory, if one was given.
+func getRecipesURL(ctx *cli.Context) string {
+	return commands.GlobalString(ctx, "recipes-url")
+}
+
+// requireRecipesDir Returns an error if the recipes are loaded from a URL.
+// Commands that use the recipe directories, rather than just the recipes,
+// must call this first.
+func requireRecipesDir(ctx *cli.Context) error {
+	if len(getRecipesURL(ctx)) > 0 {
+		return fmt.Errorf("%s needs a recipes directory, and can't be used with --recipes-url", ctx.Command.Name)
+	}
+	return nil
+}
+
+// maxRecipeBundleSize The largest recipe bundle fetched from --recipes-url.
+const maxRecipeBundleSize = 10 << 20
+
+// fetchRecipes Downloads and parses the recipe bundle at --recipes-url.
+func fetchRecipes(ctx *cli.Context) (map[string]recipes.Recipe, error) {
+	client := &http.Client{Timeout: commands.GlobalDuration(ctx, "recipes-url-timeout")}
+	return fetchBundle(client, getRecipesURL(ctx), getLoadOptions(ctx))
+}
+
+// fetchBundle Downloads and parses the recipe bundle at url using client.
+// The Authorization header is only sent over https, so that it can't be
+// read or tampered with on the way.
+func fetchBundle(client *http.Client, url string, options recipes.LoadOptions) (map[string]recipes.Recipe, error) {
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if auth := os.Getenv(recipesURLAuthEnvVar); len(auth) > 0 {
+		if request.URL.Scheme != "https" {
+			return nil, fmt.Errorf("$%s is only sent over https, and %s isn't https", recipesURLAuthEnvVar, url)
+		}
+		request.Header.Set("Authorization", auth)
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error fetching recipes from %s: %s", url, response.Status)
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(response.Body, maxRecipeBundleSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxRecipeBundleSize {
+		return nil, fmt.Errorf("the recipe bundle at %s is larger than %d bytes", url, maxRecipeBundleSize)
+	}
+
+	return recipes.ParseBundle(bytes.NewReader(data), options)
+}
@@ -0,0 +1,114 @@
+package recipes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/godarch/darch/pkg/recipes"
+)
+
+const testBundle = `{"base": {"inherits": "external:archlinux/base"}, "web": {"inherits": "base"}}`
+
+func TestFetchBundle(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		timeout time.Duration
+		err     string
+	}{
+		{
+			name: "ok",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(testBundle))
+			},
+		},
+		{
+			name: "status",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "missing", http.StatusNotFound)
+			},
+			err: "404 Not Found",
+		},
+		{
+			name: "invalid json",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"base": `))
+			},
+			err: "invalid recipe bundle",
+		},
+		{
+			name: "too large",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(strings.Repeat(" ", maxRecipeBundleSize+1)))
+			},
+			err: "larger than",
+		},
+		{
+			name: "timeout",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(200 * time.Millisecond)
+				w.Write([]byte(testBundle))
+			},
+			timeout: 50 * time.Millisecond,
+			err:     "Timeout",
+		},
+	}
+
+	for _, test := range tests {
+		server := httptest.NewServer(test.handler)
+		client := &http.Client{Timeout: 5 * time.Second}
+		if test.timeout > 0 {
+			client.Timeout = test.timeout
+		}
+		rs, err := fetchBundle(client, server.URL, recipes.LoadOptions{})
+		server.Close()
+
+		if len(test.err) > 0 {
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Fatalf("%s: expected an error containing %q, got %v", test.name, test.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: error fetching bundle %v", test.name, err)
+		}
+		if len(rs) != 2 || rs["web"].Inherits != "base" {
+			t.Fatalf("%s: unexpected recipes %v", test.name, rs)
+		}
+	}
+}
+
+func TestFetchBundleAuth(t *testing.T) {
+	os.Setenv(recipesURLAuthEnvVar, "Bearer secret")
+	defer os.Unsetenv(recipesURLAuthEnvVar)
+
+	var auth string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		w.Write([]byte(testBundle))
+	}
+
+	server := httptest.NewTLSServer(http.HandlerFunc(handler))
+	defer server.Close()
+	if _, err := fetchBundle(server.Client(), server.URL, recipes.LoadOptions{}); err != nil {
+		t.Fatalf("error fetching bundle %v", err)
+	}
+	if auth != "Bearer secret" {
+		t.Fatalf("expected the Authorization header to be sent, got %q", auth)
+	}
+
+	// The header isn't sent in the clear.
+	auth = ""
+	insecure := httptest.NewServer(http.HandlerFunc(handler))
+	defer insecure.Close()
+	if _, err := fetchBundle(insecure.Client(), insecure.URL, recipes.LoadOptions{}); err == nil || !strings.Contains(err.Error(), "only sent over https") {
+		t.Fatalf("expected an error for a non-https URL, got %v", err)
+	}
+	if len(auth) > 0 {
+		t.Fatalf("expected the Authorization header not to be sent, got %q", auth)
+	}
+}
@@ -1,6 +1,16 @@
 package recipes
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/godarch/darch/pkg/cmd/darch/commands"
+	"github.com/godarch/darch/pkg/recipes"
+	"github.com/godarch/darch/pkg/utils"
 	"github.com/urfave/cli"
 )
 
@@ -12,9 +22,44 @@ var (
 		Flags: []cli.Flag{
 			cli.StringFlag{
 				Name:  "recipes-dir, d",
-				Usage: "location of the recipes",
+				Usage: "location of the recipes, flags can also be set with DARCH_RECIPES_DIR style environment variables or in ~/.darchrc",
 				Value: ".",
 			},
+			cli.StringFlag{
+				Name:  "recipes-url",
+				Usage: "load a JSON bundle of recipes from this URL instead of the recipes directory, sending $" + recipesURLAuthEnvVar + " as the Authorization header over https",
+			},
+			cli.DurationFlag{
+				Name:  "recipes-url-timeout",
+				Usage: "how long to wait for --recipes-url",
+				Value: 30 * time.Second,
+			},
+			cli.BoolFlag{
+				Name:  "allow-case-collision",
+				Usage: "warn instead of failing when recipe names differ only in case",
+			},
+			cli.StringFlag{
+				Name:  "only",
+				Usage: "only load recipes matching the given glob, along with their parents",
+			},
+			cli.IntFlag{
+				Name:  "concurrency",
+				Usage: "the number of recipes to load at once, 1 loads them one at a time",
+				Value: runtime.NumCPU(),
+			},
+			cli.IntFlag{
+				Name:  "read-retries",
+				Usage: "the number of times to retry reading a recipe after a transient error",
+				Value: 2,
+			},
+			cli.BoolFlag{
+				Name:  "no-interpolate",
+				Usage: "don't replace ${VAR} and ${VAR:-default} in recipes with environment variables",
+			},
+			cli.BoolFlag{
+				Name:  "read-only",
+				Usage: "refuse to run commands that modify the recipes directory (scaffold)",
+			},
 		},
 		Subcommands: cli.Commands{
 			buildCommand,
@@ -23,10 +68,142 @@ var (
 			childrenCommand,
 			treeCommand,
 			builddepCommand,
+			graphCommand,
+			chainHashCommand,
+			cacheKeysCommand,
+			resolveBasesCommand,
+			brokenCommand,
+			unusedBasesCommand,
+			subgraphCommand,
+			distanceCommand,
+			scaffoldCommand,
+			validateCommand,
+			orderCommand,
+			suggestBasesCommand,
+			repoSizeCommand,
+			maxWidthCommand,
+			lineageDiffCommand,
+			rdepsCommand,
+			suspectExternalsCommand,
+			partitionsCommand,
+			forestsCommand,
+			dockerfileCommand,
+			argOriginCommand,
+			byArgCommand,
+			baseCommand,
+			compareTreesCommand,
+			closureCommand,
+			breadcrumbsCommand,
+			verifyGraphCommand,
+			wavesCommand,
+			ghaMatrixCommand,
+			diameterCommand,
+			descendantsDiffCommand,
+			blastRadiusCommand,
+			byRegistryCommand,
+			inspectCommand,
+			commonPrefixCommand,
+			rebuildSetCommand,
+			etaCommand,
+			nearbyCommand,
+			rootsCommand,
+			leavesCommand,
+			orphansCommand,
 		},
 	}
 )
 
 func getRecipesDir(ctx *cli.Context) string {
-	return ctx.GlobalString("recipes-dir")
+	return utils.ExpandPath(commands.GlobalString(ctx, "recipes-dir"))
+}
+
+func getLoadOptions(ctx *cli.Context) recipes.LoadOptions {
+	return recipes.LoadOptions{
+		AllowCaseCollision: commands.GlobalBool(ctx, "allow-case-collision"),
+		Only:               commands.GlobalString(ctx, "only"),
+		Concurrency:        commands.GlobalInt(ctx, "concurrency"),
+		ReadRetries:        commands.GlobalInt(ctx, "read-retries"),
+		NoInterpolate:      commands.GlobalBool(ctx, "no-interpolate"),
+	}
+}
+
+// checkWritable Returns an error if the recipes directory is read-only.
+// Commands that modify the recipes directory must call this first.
+func checkWritable(ctx *cli.Context) error {
+	if commands.GlobalBool(ctx, "read-only") {
+		return fmt.Errorf("%s would modify the recipes directory, which is read-only", ctx.Command.Name)
+	}
+	return nil
+}
+
+// getAllRecipes Loads and verifies all the recipes, using the global flags.
+func getAllRecipes(ctx *cli.Context) (map[string]recipes.Recipe, error) {
+	if len(getRecipesURL(ctx)) > 0 {
+		rs, err := fetchRecipes(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if problems := recipes.Validate(rs); len(problems) > 0 {
+			return nil, errors.New(problems[0].Message)
+		}
+		warnCaseCollisions(rs)
+		return rs, nil
+	}
+
+	rs, err := recipes.GetAllRecipesWithOptions(getRecipesDir(ctx), getLoadOptions(ctx))
+	if err != nil {
+		return nil, err
+	}
+	warnCaseCollisions(rs)
+	return rs, nil
+}
+
+// parseAllRecipes Loads all the recipes without verifying them, using the global flags.
+func parseAllRecipes(ctx *cli.Context) (map[string]recipes.Recipe, error) {
+	if len(getRecipesURL(ctx)) > 0 {
+		rs, err := fetchRecipes(ctx)
+		if err != nil {
+			return nil, err
+		}
+		warnCaseCollisions(rs)
+		return rs, nil
+	}
+
+	rs, err := recipes.ParseAllRecipesWithOptions(getRecipesDir(ctx), getLoadOptions(ctx))
+	if _, ok := err.(*recipes.LoadError); ok {
+		// The recipes that could be read are still returned.
+		warnCaseCollisions(rs)
+		return rs, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	warnCaseCollisions(rs)
+	return rs, nil
+}
+
+// checkLoaded Returns the error returned by parseAllRecipes, unless it is a
+// *recipes.LoadError for other recipes than recipeName, which can be ignored
+// by commands that only need the one recipe.
+func checkLoaded(err error, recipeName string) error {
+	loadErr, ok := err.(*recipes.LoadError)
+	if !ok {
+		return err
+	}
+	for _, problem := range loadErr.Problems {
+		if problem.Recipes[0] == recipeName {
+			return errors.New(problem.Message)
+		}
+	}
+	return nil
+}
+
+func warnCaseCollisions(rs map[string]recipes.Recipe) {
+	names := make([]string, 0)
+	for name := range rs {
+		names = append(names, name)
+	}
+	for _, collision := range recipes.CaseCollisions(names) {
+		fmt.Fprintf(os.Stderr, "warning: recipes %s differ only in case\n", strings.Join(collision, ", "))
+	}
 }
@@ -0,0 +1,139 @@
+package recipes
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/godarch/darch/pkg/cmd/darch/commands"
+	"github.com/urfave/cli"
+)
+
+type recipeSize struct {
+	Name  string `json:"name"`
+	Bytes int64  `json:"bytes"`
+}
+
+var repoSizeCommand = cli.Command{
+	Name:  "repo-size",
+	Usage: "print the total size of all recipe directories, and the largest ones",
+	Flags: []cli.Flag{
+		cli.IntFlag{
+			Name:  "top",
+			Usage: "the number of largest recipes to print",
+			Value: 10,
+		},
+		cli.StringFlag{
+			Name:  "output, o",
+			Usage: "the output format (text, json)",
+			Value: "text",
+		},
+	},
+	Action: func(clicontext *cli.Context) error {
+		var (
+			top    = clicontext.Int("top")
+			output = clicontext.String("output")
+		)
+
+		if output != "text" && output != "json" {
+			return fmt.Errorf("unknown output %s, must be text or json", output)
+		}
+
+		if err := requireRecipesDir(clicontext); err != nil {
+			return err
+		}
+
+		rs, err := getAllRecipes(clicontext)
+		if err != nil {
+			return err
+		}
+
+		sizes := make([]recipeSize, 0, len(rs))
+		for _, r := range rs {
+			sizes = append(sizes, recipeSize{Name: r.Name})
+		}
+
+		var (
+			wg          sync.WaitGroup
+			indexes     = make(chan int)
+			errs        = make([]error, len(sizes))
+			concurrency = commands.GlobalInt(clicontext, "concurrency")
+		)
+		if concurrency <= 0 {
+			concurrency = runtime.NumCPU()
+		}
+		for worker := 0; worker < concurrency; worker++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range indexes {
+					sizes[i].Bytes, errs[i] = directorySize(rs[sizes[i].Name].RecipeDir)
+				}
+			}()
+		}
+		for i := range sizes {
+			indexes <- i
+		}
+		close(indexes)
+		wg.Wait()
+
+		for _, err := range errs {
+			if err != nil {
+				return err
+			}
+		}
+
+		var total int64
+		for _, size := range sizes {
+			total += size.Bytes
+		}
+
+		sort.Slice(sizes, func(i, j int) bool {
+			if sizes[i].Bytes == sizes[j].Bytes {
+				return sizes[i].Name < sizes[j].Name
+			}
+			return sizes[i].Bytes > sizes[j].Bytes
+		})
+
+		if output == "json" {
+			jsonData, err := json.MarshalIndent(struct {
+				TotalBytes int64        `json:"totalBytes"`
+				Recipes    []recipeSize `json:"recipes"`
+			}{total, sizes}, "", "    ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(jsonData))
+			return nil
+		}
+
+		fmt.Printf("total: %d bytes in %d recipes\n", total, len(sizes))
+		for i, size := range sizes {
+			if i >= top {
+				break
+			}
+			fmt.Printf("%d\t%s\n", size.Bytes, size.Name)
+		}
+
+		return nil
+	},
+}
+
+// directorySize Returns the total size of the regular files in a directory.
+func directorySize(directory string) (int64, error) {
+	var size int64
+	err := filepath.Walk(directory, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
@@ -0,0 +1,130 @@
+package recipes
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/containerd/containerd/remotes"
+	"github.com/godarch/darch/pkg/cmd/darch/commands"
+	"github.com/godarch/darch/pkg/darchrc"
+	"github.com/godarch/darch/pkg/recipes"
+	"github.com/godarch/darch/pkg/reference"
+	"github.com/urfave/cli"
+)
+
+var resolveBasesCommand = cli.Command{
+	Name:  "resolve-bases",
+	Usage: "resolve the external images recipes inherit to their current digest",
+	Flags: append([]cli.Flag{
+		cli.DurationFlag{
+			Name:  "timeout",
+			Usage: "how long to wait on the registry for each image",
+			Value: 30 * time.Second,
+		},
+	}, resolveBasesRegistryFlags()...),
+	Action: func(clicontext *cli.Context) error {
+		var (
+			timeout = clicontext.Duration("timeout")
+		)
+
+		rs, err := getAllRecipes(clicontext)
+		if err != nil {
+			return err
+		}
+
+		externalImages := make([]string, 0)
+		for externalImage := range recipes.ExternalBases(rs) {
+			// Images referenced by digest are already pinned.
+			if !strings.Contains(externalImage, "@") {
+				externalImages = append(externalImages, externalImage)
+			}
+		}
+		sort.Strings(externalImages)
+
+		resolver, err := commands.GetResolver(clicontext)
+		if err != nil {
+			return err
+		}
+
+		var (
+			wg          sync.WaitGroup
+			indexes     = make(chan int)
+			pinned      = make([]string, len(externalImages))
+			errs        = make([]error, len(externalImages))
+			failure     = false
+			concurrency = commands.GlobalInt(clicontext, "concurrency")
+		)
+		if concurrency <= 0 {
+			concurrency = runtime.NumCPU()
+		}
+		for worker := 0; worker < concurrency; worker++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range indexes {
+					pinned[i], errs[i] = resolveBase(resolver, externalImages[i], timeout)
+				}
+			}()
+		}
+		for i := range externalImages {
+			indexes <- i
+		}
+		close(indexes)
+		wg.Wait()
+
+		for i, externalImage := range externalImages {
+			if errs[i] != nil {
+				failure = true
+				fmt.Fprintf(os.Stderr, "error resolving %s: %s\n", externalImage, errs[i])
+				continue
+			}
+			fmt.Printf("%s -> %s\n", externalImage, pinned[i])
+		}
+
+		if failure {
+			return fmt.Errorf("failed to resolve all external images")
+		}
+
+		return nil
+	},
+}
+
+// resolveBasesRegistryFlags Returns the registry flags, with --user also
+// read from its DARCH_USER environment variable. This is only done here, as
+// resolve-bases is often run unattended, unlike pushing and pulling images.
+func resolveBasesRegistryFlags() []cli.Flag {
+	flags := make([]cli.Flag, 0, len(commands.RegistryFlags))
+	for _, flag := range commands.RegistryFlags {
+		if user, ok := flag.(cli.StringFlag); ok && user.Name == "user,u" {
+			user.EnvVar = darchrc.EnvVar("user")
+			flag = user
+		}
+		flags = append(flags, flag)
+	}
+	return flags
+}
+
+// resolveBase Resolves an external image to its current digest, returning
+// it pinned to the digest.
+func resolveBase(resolver remotes.Resolver, externalImage string, timeout time.Duration) (string, error) {
+	imageRef, err := reference.ParseImage(externalImage)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	_, desc, err := resolver.Resolve(ctx, imageRef.FullName())
+	if err != nil {
+		return "", err
+	}
+
+	return imageRef.Name + "@" + desc.Digest.String(), nil
+}
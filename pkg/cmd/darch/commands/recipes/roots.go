@@ -0,0 +1,85 @@
+package recipes
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/godarch/darch/pkg/recipes"
+	"github.com/urfave/cli"
+)
+
+var rootsCommand = cli.Command{
+	Name:  "roots",
+	Usage: "list the distinct external images the recipes are built on",
+	Flags: []cli.Flag{
+		cli.BoolFlag{
+			Name:  "recipes",
+			Usage: "list the recipes directly inheriting an external image instead, which are built first",
+		},
+		cli.BoolFlag{
+			Name:  "with-count",
+			Usage: "also print the number of recipes directly inheriting each external image",
+		},
+		cli.BoolFlag{
+			Name:  "dedupe-externals",
+			Usage: "group equivalent external images, such as ubuntu and docker.io/library/ubuntu:latest, together",
+		},
+	},
+	Action: func(clicontext *cli.Context) error {
+		return runRoots(clicontext, os.Stdout)
+	},
+}
+
+// runRoots Writes the external images the recipes are built on, or the
+// recipes directly inheriting them, to w.
+func runRoots(clicontext *cli.Context, w io.Writer) error {
+	var (
+		withCount   = clicontext.Bool("with-count")
+		recipeRoots = clicontext.Bool("recipes")
+		dedupe      = clicontext.Bool("dedupe-externals")
+	)
+
+	if withCount && recipeRoots {
+		return fmt.Errorf("--with-count can't be used with --recipes")
+	}
+
+	rs, err := getAllRecipes(clicontext)
+	if err != nil {
+		return err
+	}
+
+	externalBases := recipes.ExternalBases(rs)
+	if dedupe {
+		externalBases = dedupeExternalBases(externalBases)
+	}
+
+	if recipeRoots {
+		names := make([]string, 0)
+		for _, dependents := range externalBases {
+			names = append(names, dependents...)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintln(w, name)
+		}
+		return nil
+	}
+
+	externalImages := make([]string, 0, len(externalBases))
+	for externalImage := range externalBases {
+		externalImages = append(externalImages, externalImage)
+	}
+	sort.Strings(externalImages)
+
+	for _, externalImage := range externalImages {
+		if withCount {
+			fmt.Fprintf(w, "%s %d\n", externalImage, len(externalBases[externalImage]))
+		} else {
+			fmt.Fprintln(w, externalImage)
+		}
+	}
+
+	return nil
+}
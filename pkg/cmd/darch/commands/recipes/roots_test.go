@@ -0,0 +1,44 @@
+package recipes
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/godarch/darch/pkg/utils"
+	"github.com/urfave/cli"
+)
+
+func TestDedupeExternalsFlag(t *testing.T) {
+	recipesDir := path.Join(os.TempDir(), utils.NewID())
+	defer os.RemoveAll(recipesDir)
+
+	writeTestRecipes(t, recipesDir, map[string]string{
+		"tools": "external:ubuntu",
+		"web":   "external:docker.io/library/ubuntu:latest",
+	})
+
+	tests := []struct {
+		command  cli.Command
+		run      func(*cli.Context, io.Writer) error
+		args     []string
+		expected string
+	}{
+		{rootsCommand, runRoots, nil, "docker.io/library/ubuntu:latest\nubuntu\n"},
+		{rootsCommand, runRoots, []string{"--dedupe-externals", "--with-count"}, "docker.io/library/ubuntu:latest 2\n"},
+		{listCommand, runList, []string{"--external"}, "docker.io/library/ubuntu:latest\nubuntu\n"},
+		{listCommand, runList, []string{"--external", "--dedupe-externals"}, "docker.io/library/ubuntu:latest\n"},
+	}
+
+	for _, test := range tests {
+		var buffer bytes.Buffer
+		if err := test.run(testContext(t, test.command, recipesDir, test.args...), &buffer); err != nil {
+			t.Fatalf("error running %s %v", test.command.Name, err)
+		}
+		if buffer.String() != test.expected {
+			t.Fatalf("expected %q for %s %v, got %q", test.expected, test.command.Name, test.args, buffer.String())
+		}
+	}
+}
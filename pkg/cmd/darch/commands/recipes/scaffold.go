@@ -0,0 +1,105 @@
+package recipes
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+
+	"github.com/godarch/darch/pkg/recipes"
+	"github.com/godarch/darch/pkg/utils"
+	"github.com/urfave/cli"
+)
+
+var scaffoldCommand = cli.Command{
+	Name:      "scaffold",
+	Usage:     "generate a starter script for a recipe",
+	ArgsUsage: "<recipe>",
+	Flags: []cli.Flag{
+		cli.BoolFlag{
+			Name:  "all",
+			Usage: "generate a script for every recipe that doesn't have one",
+		},
+		cli.BoolFlag{
+			Name:  "force",
+			Usage: "overwrite existing scripts",
+		},
+		cli.StringFlag{
+			Name:  "image-prefix, p",
+			Usage: "the value to prepend to inherited recipe image names",
+			Value: "",
+		},
+	},
+	Action: func(clicontext *cli.Context) error {
+		return runScaffold(clicontext, os.Stdout)
+	},
+}
+
+// runScaffold Generates the starter scripts, writing the path of each
+// generated script to w.
+func runScaffold(clicontext *cli.Context, w io.Writer) error {
+	var (
+		recipeName  = clicontext.Args().First()
+		all         = clicontext.Bool("all")
+		force       = clicontext.Bool("force")
+		imagePrefix = clicontext.String("image-prefix")
+	)
+
+	if len(recipeName) == 0 && !all {
+		return fmt.Errorf("You must provide a recipe name or --all")
+	}
+
+	if err := checkWritable(clicontext); err != nil {
+		return err
+	}
+
+	if err := requireRecipesDir(clicontext); err != nil {
+		return err
+	}
+
+	rs, err := getAllRecipes(clicontext)
+	if err != nil {
+		return err
+	}
+
+	recipeNames := make([]string, 0)
+	if all {
+		for _, r := range rs {
+			recipeNames = append(recipeNames, r.Name)
+		}
+		sort.Strings(recipeNames)
+	} else {
+		if _, ok := rs[recipeName]; !ok {
+			return fmt.Errorf("Recipe %s doesn't exist", recipeName)
+		}
+		recipeNames = append(recipeNames, recipeName)
+	}
+
+	for _, name := range recipeNames {
+		scriptPath := path.Join(rs[name].RecipeDir, "script")
+		if utils.FileExists(scriptPath) && !force {
+			if !all {
+				return fmt.Errorf("%s already exists, use --force to overwrite it", scriptPath)
+			}
+			continue
+		}
+		err = ioutil.WriteFile(scriptPath, []byte(scaffoldScript(rs[name], imagePrefix)), 0755)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "wrote %s\n", scriptPath)
+	}
+
+	return nil
+}
+
+// scaffoldScript Returns a starter script for the recipe, noting the image it is built on.
+func scaffoldScript(recipe recipes.Recipe, imagePrefix string) string {
+	parent := recipe.Inherits
+	if !recipe.InheritsExternal {
+		parent = imagePrefix + parent
+	}
+	return fmt.Sprintf("#!/usr/bin/env bash\nset -e\n\n# %s is built on top of %s.\n", recipe.Name, parent)
+}
@@ -0,0 +1,87 @@
+package recipes
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/godarch/darch/pkg/utils"
+)
+
+func TestRunScaffold(t *testing.T) {
+	recipesDir := path.Join(os.TempDir(), utils.NewID())
+	defer os.RemoveAll(recipesDir)
+
+	writeTestRecipes(t, recipesDir, map[string]string{
+		"base": "external:archlinux/base",
+		"web":  "base",
+	})
+	scriptPath := path.Join(recipesDir, "web", "script")
+
+	readScript := func() string {
+		data, err := ioutil.ReadFile(scriptPath)
+		if err != nil {
+			t.Fatalf("error reading script %v", err)
+		}
+		return string(data)
+	}
+
+	// Creating
+	var buffer bytes.Buffer
+	if err := runScaffold(testContext(t, scaffoldCommand, recipesDir, "--image-prefix", "darch/", "web"), &buffer); err != nil {
+		t.Fatalf("error running scaffold %v", err)
+	}
+	if buffer.String() != "wrote "+scriptPath+"\n" {
+		t.Fatalf("unexpected output %q", buffer.String())
+	}
+	if !strings.Contains(readScript(), "# web is built on top of darch/base.") {
+		t.Fatalf("unexpected script %q", readScript())
+	}
+
+	// Not overwriting without --force
+	if err := ioutil.WriteFile(scriptPath, []byte("custom"), 0755); err != nil {
+		t.Fatalf("error writing script %v", err)
+	}
+	buffer.Reset()
+	err := runScaffold(testContext(t, scaffoldCommand, recipesDir, "web"), &buffer)
+	if err == nil || !strings.Contains(err.Error(), "use --force") {
+		t.Fatalf("expected an error for an existing script, got %v", err)
+	}
+	if readScript() != "custom" {
+		t.Fatalf("expected the script not to be overwritten, got %q", readScript())
+	}
+
+	// --all skips the existing script
+	buffer.Reset()
+	if err := runScaffold(testContext(t, scaffoldCommand, recipesDir, "--all"), &buffer); err != nil {
+		t.Fatalf("error running scaffold %v", err)
+	}
+	if buffer.String() != "wrote "+path.Join(recipesDir, "base", "script")+"\n" || readScript() != "custom" {
+		t.Fatalf("expected only base to be written, got %q", buffer.String())
+	}
+
+	// Overwriting with --force
+	buffer.Reset()
+	if err := runScaffold(testContext(t, scaffoldCommand, recipesDir, "--force", "web"), &buffer); err != nil {
+		t.Fatalf("error running scaffold %v", err)
+	}
+	if !strings.Contains(readScript(), "# web is built on top of base.") {
+		t.Fatalf("expected the script to be overwritten, got %q", readScript())
+	}
+
+	// Refusing a read-only recipes directory
+	if err := os.Remove(scriptPath); err != nil {
+		t.Fatalf("error removing script %v", err)
+	}
+	ctx := testGlobalContext(t, scaffoldCommand, []string{"--recipes-dir", recipesDir, "--read-only"}, "web")
+	err = runScaffold(ctx, &buffer)
+	if err == nil || !strings.Contains(err.Error(), "scaffold would modify the recipes directory") {
+		t.Fatalf("expected an error for a read-only recipes directory, got %v", err)
+	}
+	if utils.FileExists(scriptPath) {
+		t.Fatal("expected no script to be written to a read-only recipes directory")
+	}
+}
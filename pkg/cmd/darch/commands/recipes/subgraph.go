@@ -0,0 +1,90 @@
+package recipes
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/godarch/darch/pkg/recipes"
+	"github.com/urfave/cli"
+)
+
+var subgraphCommand = cli.Command{
+	Name:      "subgraph",
+	Usage:     "output the graph around a single recipe",
+	ArgsUsage: "<recipe>",
+	Flags: []cli.Flag{
+		cli.IntFlag{
+			Name:  "up",
+			Usage: "the number of levels of parents to include",
+			Value: 1,
+		},
+		cli.IntFlag{
+			Name:  "down",
+			Usage: "the number of levels of children to include",
+			Value: 1,
+		},
+		cli.StringFlag{
+			Name:  "format",
+			Usage: "the output format (" + strings.Join(graphFormats, ", ") + ")",
+			Value: "dot",
+		},
+	},
+	Action: func(clicontext *cli.Context) error {
+		var (
+			recipeName = clicontext.Args().First()
+			up         = clicontext.Int("up")
+			down       = clicontext.Int("down")
+			format     = clicontext.String("format")
+		)
+
+		if len(recipeName) == 0 {
+			return fmt.Errorf("You must provide a recipe name")
+		}
+
+		if up < 0 {
+			return fmt.Errorf("--up must not be negative")
+		}
+
+		if down < 0 {
+			return fmt.Errorf("--down must not be negative")
+		}
+
+		rs, err := getAllRecipes(clicontext)
+		if err != nil {
+			return err
+		}
+
+		current, ok := rs[recipeName]
+		if !ok {
+			return fmt.Errorf("Recipe %s doesn't exist", recipeName)
+		}
+
+		chain, err := recipes.ResolveChain(current, rs)
+		if err != nil {
+			return err
+		}
+
+		subset := make(map[string]recipes.Recipe, 0)
+		for i := 0; i < len(chain) && i <= up; i++ {
+			subset[chain[i].Name] = chain[i]
+		}
+		for _, descendant := range recipes.Descendants(current, rs, down) {
+			subset[descendant] = rs[descendant]
+		}
+
+		g := newRecipeGraph(subset)
+		// The external image is one level above the last recipe in the chain.
+		if up < len(chain) {
+			g = g.withoutExternalOf(chain[len(chain)-1].Name)
+		}
+
+		output, err := renderGraph(format, g)
+		if err != nil {
+			return err
+		}
+
+		fmt.Print(output)
+
+		return nil
+	},
+}
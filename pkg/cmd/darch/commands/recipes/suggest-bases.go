@@ -0,0 +1,50 @@
+package recipes
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/godarch/darch/pkg/recipes"
+	"github.com/urfave/cli"
+)
+
+var suggestBasesCommand = cli.Command{
+	Name:  "suggest-bases",
+	Usage: "suggest a shared base recipe for external images inherited by many recipes",
+	Flags: []cli.Flag{
+		cli.IntFlag{
+			Name:  "min-children",
+			Usage: "the number of recipes directly inheriting an external image before a shared base is suggested",
+			Value: 2,
+		},
+	},
+	Action: func(clicontext *cli.Context) error {
+		var (
+			minChildren = clicontext.Int("min-children")
+		)
+
+		rs, err := getAllRecipes(clicontext)
+		if err != nil {
+			return err
+		}
+
+		children := recipes.ExternalBases(rs)
+
+		externalImages := make([]string, 0)
+		for externalImage := range children {
+			externalImages = append(externalImages, externalImage)
+		}
+		sort.Strings(externalImages)
+
+		for _, externalImage := range externalImages {
+			names := children[externalImage]
+			if len(names) < minChildren {
+				continue
+			}
+			fmt.Printf("%d recipes directly inherit external %s; consider a shared base: %s\n", len(names), externalImage, strings.Join(names, ", "))
+		}
+
+		return nil
+	},
+}
@@ -0,0 +1,75 @@
+package recipes
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/godarch/darch/pkg/recipes"
+	"github.com/urfave/cli"
+)
+
+var suspectExternalsCommand = cli.Command{
+	Name:  "suspect-externals",
+	Usage: "list recipes inheriting an external image that looks like one of the recipes",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "image-prefix, p",
+			Usage: "the value prepended to built image names",
+			Value: "",
+		},
+	},
+	Action: func(clicontext *cli.Context) error {
+		var (
+			imagePrefix = clicontext.String("image-prefix")
+		)
+
+		rs, err := getAllRecipes(clicontext)
+		if err != nil {
+			return err
+		}
+
+		names := make([]string, 0)
+		for name := range rs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			r := rs[name]
+			if !r.InheritsExternal {
+				continue
+			}
+			if internal, ok := resemblesRecipe(r.Inherits, imagePrefix, rs); ok {
+				fmt.Printf("%s: inherits external:%s, which looks like recipe %s\n", r.Name, r.Inherits, internal)
+			}
+		}
+
+		return nil
+	},
+}
+
+// resemblesRecipe Returns the recipe an external image reference looks like a
+// build of, ignoring the tag or digest and the image prefix.
+func resemblesRecipe(externalImage string, imagePrefix string, rs map[string]recipes.Recipe) (string, bool) {
+	name := externalImage
+	if i := strings.Index(name, "@"); i >= 0 {
+		name = name[:i]
+	}
+	// A colon after the last slash starts the tag, before it is a registry port.
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name = name[:i]
+	}
+
+	if _, ok := rs[name]; ok {
+		return name, true
+	}
+
+	if len(imagePrefix) > 0 && strings.HasPrefix(name, imagePrefix) {
+		if _, ok := rs[strings.TrimPrefix(name, imagePrefix)]; ok {
+			return strings.TrimPrefix(name, imagePrefix), true
+		}
+	}
+
+	return "", false
+}
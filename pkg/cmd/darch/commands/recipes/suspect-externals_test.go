@@ -0,0 +1,35 @@
+package recipes
+
+import (
+	"testing"
+
+	"github.com/godarch/darch/pkg/recipes"
+)
+
+func TestResemblesRecipe(t *testing.T) {
+	rs := map[string]recipes.Recipe{
+		"base": {Name: "base", Inherits: "archlinux/base", InheritsExternal: true},
+		"web":  {Name: "web", Inherits: "base"},
+	}
+
+	tests := []struct {
+		externalImage string
+		imagePrefix   string
+		expected      string
+	}{
+		{"web", "", "web"},
+		{"web:latest", "", "web"},
+		{"web@sha256:abc", "", "web"},
+		{"registry:5000/team/web:latest", "registry:5000/team/", "web"},
+		{"registry:5000/team/web:latest", "", ""},
+		{"archlinux/base", "", ""},
+		{"ubuntu:20.04", "", ""},
+	}
+
+	for _, test := range tests {
+		internal, ok := resemblesRecipe(test.externalImage, test.imagePrefix, rs)
+		if internal != test.expected || ok != (len(test.expected) > 0) {
+			t.Fatalf("expected %q for %s, got %q", test.expected, test.externalImage, internal)
+		}
+	}
+}
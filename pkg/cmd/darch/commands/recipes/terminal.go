@@ -0,0 +1,28 @@
+package recipes
+
+import (
+	"io"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// terminalWidth Returns the number of columns of the terminal stdout is
+// connected to. Returns false if stdout isn't a terminal.
+func terminalWidth() (int, bool) {
+	winsize, err := unix.IoctlGetWinsize(int(os.Stdout.Fd()), unix.TIOCGWINSZ)
+	if err != nil || winsize.Col == 0 {
+		return 0, false
+	}
+	return int(winsize.Col), true
+}
+
+// isTerminal Returns true if w is a file connected to a terminal.
+func isTerminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	_, err := unix.IoctlGetTermios(int(file.Fd()), unix.TCGETS)
+	return err == nil
+}
@@ -1,6 +1,14 @@
 package recipes
 
 import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
 	"github.com/disiqueira/gotree"
 	"github.com/godarch/darch/pkg/recipes"
 	"github.com/godarch/darch/pkg/utils"
@@ -10,60 +18,425 @@ import (
 var treeCommand = cli.Command{
 	Name:  "tree",
 	Usage: "list all recipes in a tree",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "lineage",
+			Usage: "only show the chain of parents from the external image down to the given recipe",
+		},
+		cli.BoolFlag{
+			Name:  "explain-cycles",
+			Usage: "stop on the first cycle found, printing the recipes that form it",
+		},
+		cli.BoolFlag{
+			Name:  "summary",
+			Usage: "print a line of totals after the tree",
+		},
+		cli.BoolFlag{
+			Name:  "summarize-externals",
+			Usage: "only show the number of recipes built on each external image",
+		},
+		cli.StringSliceFlag{
+			Name:  "expand",
+			Usage: "with --summarize-externals, an external image to still show in full",
+		},
+		cli.StringFlag{
+			Name:  "sort-by",
+			Usage: "how to order siblings, by name (alphabetical) or size (most descendants first)",
+			Value: "name",
+		},
+		cli.StringFlag{
+			Name:  "match",
+			Usage: "only show the recipes whose full name matches this regular expression, and their parents",
+		},
+		cli.BoolFlag{
+			Name:  "dedupe-externals",
+			Usage: "group equivalent external images, such as ubuntu and docker.io/library/ubuntu:latest, together",
+		},
+		cli.IntFlag{
+			Name:  "max-children-per-node",
+			Usage: "only show this many children of each node, followed by the number hidden, 0 shows all",
+		},
+		cli.BoolFlag{
+			Name:  "exclude-external",
+			Usage: "don't show the external images, the recipes inheriting them become the roots",
+		},
+		cli.IntFlag{
+			Name:  "max-depth",
+			Usage: "the number of levels to show below the external images, 0 only shows the external images",
+		},
+		cli.StringFlag{
+			Name:  "format",
+			Usage: "the output format, text, markdown (external images and recipes as nested headings) or one of the graph formats (" + strings.Join(graphFormats, ", ") + ")",
+			Value: "text",
+		},
+	},
 	Action: func(clicontext *cli.Context) error {
-		rs, err := recipes.GetAllRecipes(getRecipesDir(clicontext))
+		return runTree(clicontext, os.Stdout)
+	},
+}
+
+// runTree Writes the tree of recipes to w.
+func runTree(clicontext *cli.Context, w io.Writer) error {
+	var (
+		lineage       = clicontext.String("lineage")
+		explainCycles = clicontext.Bool("explain-cycles")
+		summary       = clicontext.Bool("summary")
+		summarize     = clicontext.Bool("summarize-externals")
+		expand        = clicontext.StringSlice("expand")
+		sortBy        = clicontext.String("sort-by")
+		match         = clicontext.String("match")
+		dedupe        = clicontext.Bool("dedupe-externals")
+		format        = clicontext.String("format")
+		maxChildren   = clicontext.Int("max-children-per-node")
+		maxDepth      = -1
+		exclude       = clicontext.Bool("exclude-external")
+	)
+
+	if exclude && summarize {
+		return fmt.Errorf("--exclude-external can't be used with --summarize-externals")
+	}
+
+	if clicontext.IsSet("max-depth") {
+		if maxDepth = clicontext.Int("max-depth"); maxDepth < 0 {
+			return fmt.Errorf("--max-depth must not be negative")
+		}
+	}
+
+	if format != "text" && format != "markdown" && !utils.Contains(graphFormats, format) {
+		return fmt.Errorf("unknown format %s, must be text, markdown or one of %v", format, graphFormats)
+	}
+
+	if maxChildren < 0 {
+		return fmt.Errorf("--max-children-per-node must not be negative")
+	}
+
+	if sortBy != "name" && sortBy != "size" {
+		return fmt.Errorf("unknown sort %s, must be name or size", sortBy)
+	}
+
+	if explainCycles {
+		rs, err := parseAllRecipes(clicontext)
+		if _, ok := err.(*recipes.LoadError); !ok && err != nil {
+			return err
+		}
+		if err = findFirstCycle(rs); err != nil {
+			return err
+		}
+	}
+
+	rs, err := getAllRecipes(clicontext)
+	if err != nil {
+		return err
+	}
+
+	var matches func(name string) bool
+	if len(match) > 0 {
+		expression, err := regexp.Compile("^(?:" + match + ")$")
+		if err != nil {
+			return fmt.Errorf("invalid match %s: %v", match, err)
+		}
+		matches = expression.MatchString
+		if rs, err = withParentsOf(rs, matches); err != nil {
+			return err
+		}
+	}
+
+	if len(lineage) > 0 {
+		current, ok := rs[lineage]
+		if !ok {
+			return fmt.Errorf("Recipe %s doesn't exist", lineage)
+		}
+		chain, err := recipes.ResolveChain(current, rs)
 		if err != nil {
 			return err
 		}
+		fmt.Fprintln(w, gotree.StringTree(buildLineageTree(chain)))
+		return nil
+	}
 
-		externalImages := make([]string, 0)
+	if utils.Contains(graphFormats, format) {
+		output, err := renderGraph(format, newRecipeGraph(rs))
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(w, output)
+		return nil
+	}
 
-		for _, r := range rs {
-			if r.InheritsExternal {
-				externalImages = append(externalImages, r.Inherits)
-			}
+	externalBases := recipes.ExternalBases(rs)
+	if dedupe {
+		externalBases = dedupeExternalBases(externalBases)
+	}
+
+	// this will be our root items
+	externalImages := make([]string, 0)
+	for externalImage := range externalBases {
+		externalImages = append(externalImages, externalImage)
+	}
+
+	descendants := recipes.AllDescendants(rs)
+
+	externalImageSizes := make(map[string]int, 0)
+	for externalImage, rootNames := range externalBases {
+		for _, rootName := range rootNames {
+			externalImageSizes[externalImage] += 1 + len(descendants[rootName])
 		}
+	}
 
-		// this will be our root items
-		externalImages = utils.RemoveDuplicates(externalImages)
+	var order func(names []string, size func(string) int)
+	if sortBy == "size" {
+		order = sortBySize
+	} else {
+		order = func(names []string, _ func(string) int) { sort.Strings(names) }
+	}
+	recipeSize := func(name string) int { return len(descendants[name]) }
 
-		var rootNode gotree.GTStructure
+	order(externalImages, func(name string) int { return externalImageSizes[name] })
 
-		for _, externalImage := range externalImages {
-			var externalImageNode gotree.GTStructure
-			externalImageNode.Name = externalImage
-			for _, r := range rs {
-				if r.InheritsExternal && r.Inherits == externalImage {
-					var childNode gotree.GTStructure
-					childNode.Name = r.Name
-					for _, child := range buildTreeRecursively(r, rs) {
-						childNode.Items = append(childNode.Items, child)
-					}
-					externalImageNode.Items = append(externalImageNode.Items, childNode)
-				}
-			}
+	var rootNode gotree.GTStructure
+
+	for _, externalImage := range externalImages {
+		var externalImageNode gotree.GTStructure
+		externalImageNode.Name = externalImage
+		if summarize && !utils.Contains(expand, externalImage) {
+			externalImageNode.Name = fmt.Sprintf("%s (%d recipes)", externalImage, externalImageSizes[externalImage])
+			rootNode.Items = append(rootNode.Items, externalImageNode)
+			continue
+		}
+		if maxDepth == 0 {
 			rootNode.Items = append(rootNode.Items, externalImageNode)
+			continue
+		}
+		rootNames := externalBases[externalImage]
+		order(rootNames, recipeSize)
+		for _, rootName := range rootNames {
+			var childNode gotree.GTStructure
+			childNode.Name = rootName
+			for _, child := range buildTreeRecursively(rs[rootName], rs, func(names []string) { order(names, recipeSize) }, maxDepth-1) {
+				childNode.Items = append(childNode.Items, child)
+			}
+			externalImageNode.Items = append(externalImageNode.Items, childNode)
 		}
+		rootNode.Items = append(rootNode.Items, externalImageNode)
+	}
 
-		gotree.PrintTree(rootNode)
+	if exclude {
+		rootNode = withoutExternals(rootNode, func(names []string) { order(names, recipeSize) })
+	}
 
-		return nil
-	},
+	if maxChildren > 0 {
+		capTree(&rootNode, maxChildren)
+	}
+
+	if format == "markdown" {
+		fmt.Fprint(w, renderMarkdownTree(rootNode))
+	} else {
+		if matches != nil && isTerminal(w) {
+			highlightTree(&rootNode, matches)
+		}
+		fmt.Fprintln(w, gotree.StringTree(rootNode))
+	}
+
+	if summary {
+		line, err := treeSummary(rs)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, line)
+	}
+
+	return nil
+}
+
+// treeSummary Returns a line with the number of recipes, external images and
+// leaves, and the length of the longest chain.
+func treeSummary(rs map[string]recipes.Recipe) (string, error) {
+	longest, err := recipes.LongestChain(rs)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%d recipes, %d external images, max depth %d, %d leaves",
+		len(rs),
+		len(recipes.ExternalBases(rs)),
+		len(longest),
+		len(recipes.Leaves(rs))), nil
+}
+
+// findFirstCycle Returns an error with the path of the first cycle in the recipes, if any.
+func findFirstCycle(rs map[string]recipes.Recipe) error {
+	for _, problem := range recipes.Validate(rs) {
+		switch problem.Kind {
+		case recipes.ProblemCycle:
+			return fmt.Errorf("cycle detected: %s", strings.Join(problem.Recipes, " -> "))
+		case recipes.ProblemSelfInheritance:
+			return fmt.Errorf("cycle detected: %s -> %s", problem.Recipes[0], problem.Recipes[0])
+		}
+	}
+	return nil
+}
+
+// buildLineageTree Builds a tree with a single branch, from the external
+// image at the root down to the first recipe in the chain.
+func buildLineageTree(chain []recipes.Recipe) gotree.GTStructure {
+	var node gotree.GTStructure
+	node.Name = chain[0].Name
+	for _, r := range chain[1:] {
+		var parentNode gotree.GTStructure
+		parentNode.Name = r.Name
+		parentNode.Items = []gotree.GTStructure{node}
+		node = parentNode
+	}
+
+	var externalImageNode gotree.GTStructure
+	externalImageNode.Name = chain[len(chain)-1].Inherits
+	externalImageNode.Items = []gotree.GTStructure{node}
+
+	var rootNode gotree.GTStructure
+	rootNode.Items = []gotree.GTStructure{externalImageNode}
+	return rootNode
+}
+
+var markdownSpecialChars = regexp.MustCompile("([\\\\`*_{}\\[\\]()#+\\-.!|<>])")
+
+// renderMarkdownTree Renders the items of the root node as nested headings,
+// starting at #. Levels below ###### are rendered as nested lists.
+func renderMarkdownTree(rootNode gotree.GTStructure) string {
+	var buffer bytes.Buffer
+
+	var render func(node gotree.GTStructure, level int)
+	render = func(node gotree.GTStructure, level int) {
+		name := markdownSpecialChars.ReplaceAllString(node.Name, "\\$1")
+		if level <= 6 {
+			buffer.WriteString(strings.Repeat("#", level) + " " + name + "\n\n")
+		} else {
+			buffer.WriteString(strings.Repeat("  ", level-7) + "- " + name + "\n")
+		}
+		for _, item := range node.Items {
+			render(item, level+1)
+		}
+		// Separate the last list from the heading that follows it.
+		if level == 6 && len(node.Items) > 0 {
+			buffer.WriteString("\n")
+		}
+	}
+
+	for _, item := range rootNode.Items {
+		render(item, 1)
+	}
+
+	return buffer.String()
+}
+
+// dedupeExternalBases Merges the dependents of equivalent external images,
+// keyed by the normalized external image.
+func dedupeExternalBases(externalBases map[string][]string) map[string][]string {
+	result := make(map[string][]string, len(externalBases))
+	for externalImage, dependents := range externalBases {
+		normalized := utils.NormalizeImageRef(externalImage)
+		result[normalized] = append(result[normalized], dependents...)
+	}
+	for _, dependents := range result {
+		sort.Strings(dependents)
+	}
+	return result
+}
+
+// withParentsOf Returns the recipes whose name matches, along with each
+// recipe in their chains.
+func withParentsOf(rs map[string]recipes.Recipe, matches func(string) bool) (map[string]recipes.Recipe, error) {
+	result := make(map[string]recipes.Recipe, 0)
+	for name, r := range rs {
+		if !matches(name) {
+			continue
+		}
+		chain, err := recipes.ResolveChain(r, rs)
+		if err != nil {
+			return nil, err
+		}
+		for _, parent := range chain {
+			result[parent.Name] = parent
+		}
+	}
+	return result, nil
+}
+
+// highlightTree Makes the names of the matching nodes bold.
+func highlightTree(node *gotree.GTStructure, matches func(string) bool) {
+	if matches(node.Name) {
+		node.Name = "\x1b[1m" + node.Name + "\x1b[0m"
+	}
+	for i := range node.Items {
+		highlightTree(&node.Items[i], matches)
+	}
+}
+
+// withoutExternals Replaces the external image nodes under the root with
+// the recipes inheriting them, in the given order.
+func withoutExternals(rootNode gotree.GTStructure, order func([]string)) gotree.GTStructure {
+	nodes := make(map[string]gotree.GTStructure, 0)
+	names := make([]string, 0)
+	for _, externalImageNode := range rootNode.Items {
+		for _, node := range externalImageNode.Items {
+			nodes[node.Name] = node
+			names = append(names, node.Name)
+		}
+	}
+	order(names)
+
+	var result gotree.GTStructure
+	for _, name := range names {
+		result.Items = append(result.Items, nodes[name])
+	}
+	return result
+}
+
+// capTree Replaces all but the first max children of every node with a
+// single node saying how many were hidden.
+func capTree(node *gotree.GTStructure, max int) {
+	if len(node.Items) > max {
+		hidden := len(node.Items) - max
+		node.Items = append(node.Items[:max], gotree.GTStructure{Name: fmt.Sprintf("... (+%d more)", hidden)})
+	}
+	for i := range node.Items {
+		capTree(&node.Items[i], max)
+	}
+}
+
+// sortBySize Sorts names by size, largest first, and then by name.
+func sortBySize(names []string, size func(string) int) {
+	sort.Slice(names, func(i, j int) bool {
+		if size(names[i]) != size(names[j]) {
+			return size(names[i]) > size(names[j])
+		}
+		return names[i] < names[j]
+	})
 }
 
-func buildTreeRecursively(parentDefinition recipes.Recipe, rs map[string]recipes.Recipe) []gotree.GTStructure {
+// buildTreeRecursively Builds the nodes for the children of the recipe, up
+// to maxDepth levels down (a negative maxDepth is unlimited).
+func buildTreeRecursively(parentDefinition recipes.Recipe, rs map[string]recipes.Recipe, order func([]string), maxDepth int) []gotree.GTStructure {
 	children := make([]gotree.GTStructure, 0)
+	if maxDepth == 0 {
+		return children
+	}
 
+	childNames := make([]string, 0)
 	for _, childRecipeDefinition := range rs {
-		if childRecipeDefinition.Inherits == parentDefinition.Name {
-			var childNode gotree.GTStructure
-			childNode.Name = childRecipeDefinition.Name
+		if !childRecipeDefinition.InheritsExternal && childRecipeDefinition.Inherits == parentDefinition.Name {
+			childNames = append(childNames, childRecipeDefinition.Name)
+		}
+	}
+	order(childNames)
 
-			for _, child := range buildTreeRecursively(childRecipeDefinition, rs) {
-				childNode.Items = append(childNode.Items, child)
-			}
-			children = append(children, childNode)
+	for _, childName := range childNames {
+		var childNode gotree.GTStructure
+		childNode.Name = childName
+
+		for _, child := range buildTreeRecursively(rs[childName], rs, order, maxDepth-1) {
+			childNode.Items = append(childNode.Items, child)
 		}
+		children = append(children, childNode)
 	}
 
 	return children
@@ -0,0 +1,210 @@
+package recipes
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/disiqueira/gotree"
+	"github.com/godarch/darch/pkg/recipes"
+	"github.com/godarch/darch/pkg/utils"
+)
+
+func TestFindFirstCycle(t *testing.T) {
+	rs := map[string]recipes.Recipe{
+		"base": {Name: "base", Inherits: "archlinux/base", InheritsExternal: true},
+		"a":    {Name: "a", Inherits: "b"},
+		"b":    {Name: "b", Inherits: "c"},
+		"c":    {Name: "c", Inherits: "a"},
+	}
+
+	err := findFirstCycle(rs)
+	if err == nil {
+		t.Fatal("expected a cycle to be found")
+	}
+
+	if err.Error() != "cycle detected: a -> b -> c -> a" {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	delete(rs, "c")
+	rs["b"] = recipes.Recipe{Name: "b", Inherits: "base"}
+	if err = findFirstCycle(rs); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestTreeSummary(t *testing.T) {
+	rs := map[string]recipes.Recipe{
+		"base":    {Name: "base", Inherits: "archlinux/base", InheritsExternal: true},
+		"common":  {Name: "common", Inherits: "base"},
+		"desktop": {Name: "desktop", Inherits: "common"},
+		"server":  {Name: "server", Inherits: "common"},
+		"tools":   {Name: "tools", Inherits: "ubuntu:20.04", InheritsExternal: true},
+	}
+
+	line, err := treeSummary(rs)
+	if err != nil {
+		t.Fatalf("error summarizing tree %v", err)
+	}
+
+	if line != "5 recipes, 2 external images, max depth 3, 3 leaves" {
+		t.Fatalf("unexpected summary %s", line)
+	}
+}
+
+func TestSortBySize(t *testing.T) {
+	sizes := map[string]int{"a": 1, "b": 3, "c": 1, "d": 0}
+	names := []string{"d", "c", "b", "a"}
+
+	sortBySize(names, func(name string) int { return sizes[name] })
+
+	if !reflect.DeepEqual(names, []string{"b", "a", "c", "d"}) {
+		t.Fatalf("unexpected order %v", names)
+	}
+}
+
+func TestWithParentsOf(t *testing.T) {
+	rs := map[string]recipes.Recipe{
+		"base":    {Name: "base", Inherits: "archlinux/base", InheritsExternal: true},
+		"common":  {Name: "common", Inherits: "base"},
+		"web":     {Name: "web", Inherits: "common"},
+		"web-api": {Name: "web-api", Inherits: "web"},
+		"desktop": {Name: "desktop", Inherits: "common"},
+		"tools":   {Name: "tools", Inherits: "ubuntu:20.04", InheritsExternal: true},
+	}
+
+	result, err := withParentsOf(rs, func(name string) bool { return name == "web" })
+	if err != nil {
+		t.Fatalf("error filtering recipes %v", err)
+	}
+
+	names := make([]string, 0)
+	for name := range result {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if !reflect.DeepEqual(names, []string{"base", "common", "web"}) {
+		t.Fatalf("unexpected recipes %v", names)
+	}
+}
+
+func TestDedupeExternalBases(t *testing.T) {
+	deduped := dedupeExternalBases(map[string][]string{
+		"ubuntu":                          {"tools"},
+		"docker.io/library/ubuntu:latest": {"build", "web"},
+		"ubuntu:20.04":                    {"legacy"},
+	})
+
+	expected := map[string][]string{
+		"docker.io/library/ubuntu:latest": {"build", "tools", "web"},
+		"docker.io/library/ubuntu:20.04":  {"legacy"},
+	}
+	if !reflect.DeepEqual(deduped, expected) {
+		t.Fatalf("expected %v, got %v", expected, deduped)
+	}
+}
+
+func TestRenderMarkdownTree(t *testing.T) {
+	node := func(name string, items ...gotree.GTStructure) gotree.GTStructure {
+		return gotree.GTStructure{Name: name, Items: items}
+	}
+
+	rootNode := node("", node("archlinux/base",
+		node("base_image", node("l3", node("l4", node("l5", node("l6", node("l7", node("l8")))))))))
+
+	expected := "# archlinux/base\n\n" +
+		"## base\\_image\n\n" +
+		"### l3\n\n" +
+		"#### l4\n\n" +
+		"##### l5\n\n" +
+		"###### l6\n\n" +
+		"- l7\n" +
+		"  - l8\n" +
+		"\n"
+
+	if markdown := renderMarkdownTree(rootNode); markdown != expected {
+		t.Fatalf("expected %q, got %q", expected, markdown)
+	}
+}
+
+func TestCapTree(t *testing.T) {
+	var rootNode gotree.GTStructure
+	rootNode.Items = []gotree.GTStructure{{
+		Name: "archlinux/base",
+		Items: []gotree.GTStructure{
+			{Name: "a", Items: []gotree.GTStructure{{Name: "a1"}, {Name: "a2"}}},
+			{Name: "b"},
+			{Name: "c"},
+		},
+	}}
+
+	capTree(&rootNode, 2)
+
+	expected := gotree.GTStructure{Items: []gotree.GTStructure{{
+		Name: "archlinux/base",
+		Items: []gotree.GTStructure{
+			{Name: "a", Items: []gotree.GTStructure{{Name: "a1"}, {Name: "a2"}}},
+			{Name: "b"},
+			{Name: "... (+1 more)"},
+		},
+	}}}
+	if !reflect.DeepEqual(rootNode, expected) {
+		t.Fatalf("expected %v, got %v", expected, rootNode)
+	}
+}
+
+func TestWithoutExternals(t *testing.T) {
+	var rootNode gotree.GTStructure
+	rootNode.Items = []gotree.GTStructure{
+		{Name: "archlinux/base", Items: []gotree.GTStructure{{Name: "desktop", Items: []gotree.GTStructure{{Name: "kde"}}}}},
+		{Name: "ubuntu", Items: []gotree.GTStructure{{Name: "base"}}},
+	}
+
+	result := withoutExternals(rootNode, sort.Strings)
+
+	expected := gotree.GTStructure{Items: []gotree.GTStructure{
+		{Name: "base"},
+		{Name: "desktop", Items: []gotree.GTStructure{{Name: "kde"}}},
+	}}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("expected %v, got %v", expected, result)
+	}
+}
+
+func TestRunTree(t *testing.T) {
+	recipesDir := path.Join(os.TempDir(), utils.NewID())
+	defer os.RemoveAll(recipesDir)
+
+	writeTestRecipes(t, recipesDir, map[string]string{
+		"base":    "external:archlinux/base",
+		"server":  "base",
+		"web":     "server",
+		"desktop": "base",
+	})
+
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{nil, "\n└── archlinux/base\n    └── base\n        ├── desktop\n        └── server\n            └── web\n\n"},
+		{[]string{"--match", "web"}, "\n└── archlinux/base\n    └── base\n        └── server\n            └── web\n\n"},
+		{[]string{"--exclude-external", "--max-depth", "2"}, "\n└── base\n    ├── desktop\n    └── server\n\n"},
+		{[]string{"--lineage", "web"}, "\n└── archlinux/base\n    └── base\n        └── server\n            └── web\n\n"},
+		{[]string{"--format", "markdown", "--max-depth", "1"}, "# archlinux/base\n\n## base\n\n"},
+	}
+
+	for _, test := range tests {
+		var buffer bytes.Buffer
+		if err := runTree(testContext(t, treeCommand, recipesDir, test.args...), &buffer); err != nil {
+			t.Fatalf("error running tree %v", err)
+		}
+		if buffer.String() != test.expected {
+			t.Fatalf("expected %q for %v, got %q", test.expected, test.args, buffer.String())
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package recipes
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/godarch/darch/pkg/recipes"
+	"github.com/godarch/darch/pkg/utils"
+	"github.com/urfave/cli"
+)
+
+var unusedBasesCommand = cli.Command{
+	Name:  "unused-bases",
+	Usage: "list the external images in an allowlist that no recipe inherits",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "allowlist",
+			Usage: "a file with one external image per line, or - to read from stdin",
+		},
+	},
+	Action: func(clicontext *cli.Context) error {
+		var (
+			allowlist = clicontext.String("allowlist")
+		)
+
+		if len(allowlist) == 0 {
+			return fmt.Errorf("You must provide an allowlist")
+		}
+
+		var (
+			allowed []string
+			err     error
+		)
+		if allowlist == "-" {
+			allowed, err = readLines(os.Stdin)
+		} else {
+			allowed, err = utils.GetFileLines(allowlist)
+		}
+		if err != nil {
+			return err
+		}
+
+		rs, err := getAllRecipes(clicontext)
+		if err != nil {
+			return err
+		}
+
+		used := recipes.ExternalBases(rs)
+
+		results := make([]string, 0)
+		for _, externalImage := range allowed {
+			externalImage = strings.TrimSpace(externalImage)
+			if len(externalImage) == 0 {
+				continue
+			}
+			if _, ok := used[externalImage]; !ok {
+				results = append(results, externalImage)
+			}
+		}
+
+		results = utils.RemoveDuplicates(results)
+		sort.Strings(results)
+
+		for _, result := range results {
+			fmt.Println(result)
+		}
+
+		return nil
+	},
+}
+
+func readLines(reader io.Reader) ([]string, error) {
+	result := []string{}
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		result = append(result, scanner.Text())
+	}
+	return result, scanner.Err()
+}
@@ -0,0 +1,151 @@
+package recipes
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/godarch/darch/pkg/recipes"
+	"github.com/godarch/darch/pkg/utils"
+	"github.com/urfave/cli"
+)
+
+// slowestResolveCount The number of recipes printed by --verbose.
+const slowestResolveCount = 5
+
+var validateCommand = cli.Command{
+	Name:  "validate",
+	Usage: "check recipes for problems, exiting non-zero if any are found",
+	Flags: []cli.Flag{
+		cli.BoolFlag{
+			Name:  "list-broken",
+			Usage: "only print the names of the recipes with problems",
+		},
+		cli.StringFlag{
+			Name:  "expected",
+			Usage: "a file with one recipe name per line, which must all exist",
+		},
+		cli.BoolFlag{
+			Name:  "exact",
+			Usage: "with --expected, also fail on recipes that aren't in the file",
+		},
+		cli.BoolFlag{
+			Name:  "single-base",
+			Usage: "also fail if the recipes are built on more than one external image",
+		},
+		cli.StringFlag{
+			Name:  "name-pattern",
+			Usage: "a regular expression that every recipe's full name must match",
+		},
+		cli.BoolFlag{
+			Name:  "no-confusable-names",
+			Usage: "also fail on recipe names that only differ in case or separators, such as web-server and WebServer",
+		},
+		cli.BoolFlag{
+			Name:  "explain",
+			Usage: "explain each problem, and how to fix it",
+		},
+		cli.DurationFlag{
+			Name:  "max-resolve-time",
+			Usage: "also fail if resolving any recipe's chain takes longer than this",
+		},
+		cli.BoolFlag{
+			Name:  "verbose",
+			Usage: "with --max-resolve-time, print the slowest recipes to resolve",
+		},
+	},
+	Action: func(clicontext *cli.Context) error {
+		var (
+			listBroken  = clicontext.Bool("list-broken")
+			expected    = clicontext.String("expected")
+			exact       = clicontext.Bool("exact")
+			singleBase  = clicontext.Bool("single-base")
+			namePattern = clicontext.String("name-pattern")
+			explain     = clicontext.Bool("explain")
+			confusable  = clicontext.Bool("no-confusable-names")
+			maxResolve  = clicontext.Duration("max-resolve-time")
+			verbose     = clicontext.Bool("verbose")
+		)
+
+		rs, err := parseAllRecipes(clicontext)
+		loadErr, ok := err.(*recipes.LoadError)
+		if !ok && err != nil {
+			return err
+		}
+
+		problems := recipes.Validate(rs)
+		if loadErr != nil {
+			problems = append(loadErr.Problems, problems...)
+		}
+
+		if len(expected) > 0 {
+			lines, err := utils.GetFileLines(expected)
+			if err != nil {
+				return err
+			}
+			expectedNames := make([]string, 0)
+			for _, line := range lines {
+				line = strings.TrimSpace(line)
+				if len(line) > 0 && !strings.HasPrefix(line, "#") {
+					expectedNames = append(expectedNames, line)
+				}
+			}
+			problems = append(problems, recipes.ValidateExpected(rs, expectedNames, exact)...)
+		}
+
+		if singleBase {
+			problems = append(problems, recipes.ValidateSingleBase(rs)...)
+		}
+
+		if len(namePattern) > 0 {
+			nameProblems, err := recipes.ValidateNamePattern(rs, namePattern)
+			if err != nil {
+				return err
+			}
+			problems = append(problems, nameProblems...)
+		}
+
+		if confusable {
+			problems = append(problems, recipes.ValidateConfusableNames(rs)...)
+		}
+
+		if maxResolve > 0 {
+			times := recipes.TimeResolveChains(rs)
+			problems = append(problems, recipes.ValidateResolveTime(times, maxResolve)...)
+			if verbose {
+				for i := 0; i < len(times) && i < slowestResolveCount; i++ {
+					fmt.Printf("resolved %s in %s\n", times[i].Name, times[i].Duration)
+				}
+			}
+		}
+
+		if listBroken {
+			names := make([]string, 0)
+			for _, problem := range problems {
+				for _, name := range problem.Recipes {
+					if _, ok := rs[name]; ok || problem.Kind == recipes.ProblemUnreadable || problem.Kind == recipes.ProblemInvalid {
+						names = append(names, name)
+					}
+				}
+			}
+			names = utils.RemoveDuplicates(names)
+			sort.Strings(names)
+			for _, name := range names {
+				fmt.Println(name)
+			}
+		} else {
+			for _, problem := range problems {
+				fmt.Printf("%s: %s\n", problem.Kind, problem.Message)
+				if explain {
+					fmt.Printf("  %s\n", problem.Kind.Explanation())
+				}
+			}
+		}
+
+		if len(problems) > 0 {
+			return fmt.Errorf("found %d problem(s)", len(problems))
+		}
+
+		return nil
+	},
+}
@@ -0,0 +1,75 @@
+package recipes
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/godarch/darch/pkg/recipes"
+	"github.com/urfave/cli"
+)
+
+var verifyGraphCommand = cli.Command{
+	Name:   "verify-graph",
+	Usage:  "check that the parent and child lookups agree with each other",
+	Hidden: true,
+	Action: func(clicontext *cli.Context) error {
+		// The recipes that couldn't be loaded aren't in the lookups either.
+		rs, err := parseAllRecipes(clicontext)
+		if _, ok := err.(*recipes.LoadError); !ok && err != nil {
+			return err
+		}
+
+		inconsistencies := verifyGraph(rs)
+		for _, inconsistency := range inconsistencies {
+			fmt.Println(inconsistency)
+		}
+
+		if len(inconsistencies) > 0 {
+			return fmt.Errorf("found %d inconsistencies", len(inconsistencies))
+		}
+
+		return nil
+	},
+}
+
+// verifyGraph Returns a description of every recipe that is missing from
+// the children of its parent, and every child whose recipe doesn't inherit
+// the parent it is listed under.
+func verifyGraph(rs map[string]recipes.Recipe) []string {
+	result := make([]string, 0)
+
+	children := recipes.DirectChildren(rs)
+	externalBases := recipes.ExternalBases(rs)
+
+	contains := func(names []string, name string) bool {
+		i := sort.SearchStrings(names, name)
+		return i < len(names) && names[i] == name
+	}
+
+	for _, r := range rs {
+		if r.InheritsExternal && !contains(externalBases[r.Inherits], r.Name) {
+			result = append(result, fmt.Sprintf("%s is missing from the dependents of external %s", r.Name, r.Inherits))
+		} else if !r.InheritsExternal && !contains(children[r.Inherits], r.Name) {
+			result = append(result, fmt.Sprintf("%s is missing from the children of %s", r.Name, r.Inherits))
+		}
+	}
+
+	for parent, names := range children {
+		for _, name := range names {
+			if r, ok := rs[name]; !ok || r.InheritsExternal || r.Inherits != parent {
+				result = append(result, fmt.Sprintf("%s is listed as a child of %s, but doesn't inherit it", name, parent))
+			}
+		}
+	}
+
+	for externalImage, names := range externalBases {
+		for _, name := range names {
+			if r, ok := rs[name]; !ok || !r.InheritsExternal || r.Inherits != externalImage {
+				result = append(result, fmt.Sprintf("%s is listed as a dependent of external %s, but doesn't inherit it", name, externalImage))
+			}
+		}
+	}
+
+	sort.Strings(result)
+	return result
+}
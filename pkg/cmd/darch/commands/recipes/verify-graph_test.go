@@ -0,0 +1,21 @@
+package recipes
+
+import (
+	"testing"
+
+	"github.com/godarch/darch/pkg/recipes"
+)
+
+func TestVerifyGraph(t *testing.T) {
+	rs := map[string]recipes.Recipe{
+		"base":           {Name: "base", Inherits: "archlinux/base", InheritsExternal: true},
+		"common":         {Name: "common", Inherits: "base"},
+		"desktop":        {Name: "desktop", Inherits: "common"},
+		"orphan":         {Name: "orphan", Inherits: "missing"},
+		"archlinux/base": {Name: "archlinux/base", Inherits: "base"},
+	}
+
+	if inconsistencies := verifyGraph(rs); len(inconsistencies) != 0 {
+		t.Fatalf("expected no inconsistencies, got %v", inconsistencies)
+	}
+}
@@ -0,0 +1,36 @@
+package recipes
+
+import (
+	"fmt"
+
+	"github.com/godarch/darch/pkg/recipes"
+	"github.com/urfave/cli"
+)
+
+var wavesCommand = cli.Command{
+	Name:  "waves",
+	Usage: "list all recipes grouped by their depth below an external image, starting at wave 0",
+	Action: func(clicontext *cli.Context) error {
+		rs, err := getAllRecipes(clicontext)
+		if err != nil {
+			return err
+		}
+
+		waves, err := recipes.BuildWaves(rs)
+		if err != nil {
+			return err
+		}
+
+		for i, wave := range waves {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Printf("wave %d:\n", i)
+			for _, name := range wave {
+				fmt.Printf("  %s\n", name)
+			}
+		}
+
+		return nil
+	},
+}
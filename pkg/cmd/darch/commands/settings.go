@@ -0,0 +1,82 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/godarch/darch/pkg/darchrc"
+	"github.com/urfave/cli"
+)
+
+var (
+	// LoadSettings Loads the settings global flags fall back to. This
+	// declaration allows tests to replace it, so they don't depend on
+	// ~/.darchrc.
+	LoadSettings = loadSettings
+
+	// LookupEnv Looks up the environment variables global flags fall back
+	// to. This declaration allows tests to replace it, so they don't depend
+	// on the environment they are run in.
+	LookupEnv = os.LookupEnv
+
+	settings     darchrc.Settings
+	settingsOnce sync.Once
+)
+
+func loadSettings() darchrc.Settings {
+	settings, err := darchrc.Load(darchrc.DefaultLocation())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: ignoring %s: %s\n", darchrc.DefaultLocation(), err)
+		return darchrc.Settings{}
+	}
+	return settings
+}
+
+func getSettings() darchrc.Settings {
+	settingsOnce.Do(func() {
+		settings = LoadSettings()
+	})
+	return settings
+}
+
+// GlobalString Returns the value of a global string flag, falling back to
+// the environment and ~/.darchrc when it isn't given on the command line.
+func GlobalString(ctx *cli.Context, name string) string {
+	return getSettings().LookupWithEnv(name, ctx.GlobalIsSet(name), ctx.GlobalString(name), LookupEnv)
+}
+
+// GlobalInt Same as GlobalString, for int flags.
+func GlobalInt(ctx *cli.Context, name string) int {
+	value := getSettings().LookupWithEnv(name, ctx.GlobalIsSet(name), strconv.Itoa(ctx.GlobalInt(name)), LookupEnv)
+	result, err := strconv.Atoi(value)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: ignoring invalid value %q for %s\n", value, name)
+		return ctx.GlobalInt(name)
+	}
+	return result
+}
+
+// GlobalBool Same as GlobalString, for bool flags.
+func GlobalBool(ctx *cli.Context, name string) bool {
+	value := getSettings().LookupWithEnv(name, ctx.GlobalIsSet(name), strconv.FormatBool(ctx.GlobalBool(name)), LookupEnv)
+	result, err := strconv.ParseBool(value)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: ignoring invalid value %q for %s\n", value, name)
+		return ctx.GlobalBool(name)
+	}
+	return result
+}
+
+// GlobalDuration Same as GlobalString, for duration flags.
+func GlobalDuration(ctx *cli.Context, name string) time.Duration {
+	value := getSettings().LookupWithEnv(name, ctx.GlobalIsSet(name), ctx.GlobalDuration(name).String(), LookupEnv)
+	result, err := time.ParseDuration(value)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: ignoring invalid value %q for %s\n", value, name)
+		return ctx.GlobalDuration(name)
+	}
+	return result
+}
@@ -25,6 +25,7 @@ func main() {
 	app.Usage = "A tool used to build, boot and share stateless Arch images."
 	app.Version = Version
 	app.HideVersion = true
+	configureProfiling(app)
 	app.Commands = []cli.Command{
 		images.Command,
 		recipes.Command,
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+
+	"github.com/urfave/cli"
+)
+
+var profileFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:   "cpuprofile",
+		Usage:  "write a CPU profile of the command to this file",
+		Hidden: true,
+	},
+	cli.StringFlag{
+		Name:   "memprofile",
+		Usage:  "write a heap profile to this file after the command",
+		Hidden: true,
+	},
+}
+
+var (
+	cpuProfile *os.File
+	// memProfile The file to write the heap profile to when profiling is
+	// stopped, empty once it's written.
+	memProfile string
+)
+
+// configureProfiling Adds the profiling flags to the app, starting the
+// profiles before the command and stopping them after it. cli exits
+// without running After for errors with an exit code, from the app or any
+// of its subcommands, so the profiles are also stopped before exiting.
+func configureProfiling(app *cli.App) {
+	app.Flags = append(app.Flags, profileFlags...)
+	app.Before = startProfiling
+	app.After = func(c *cli.Context) error {
+		return stopProfiling()
+	}
+
+	exit := cli.OsExiter
+	cli.OsExiter = func(code int) {
+		if err := stopProfiling(); err != nil {
+			fmt.Fprintf(os.Stderr, "darch: %s\n", err)
+		}
+		exit(code)
+	}
+}
+
+// startProfiling Starts the CPU profile, if one was requested.
+func startProfiling(c *cli.Context) error {
+	memProfile = c.GlobalString("memprofile")
+
+	file := c.GlobalString("cpuprofile")
+	if len(file) == 0 {
+		return nil
+	}
+
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+
+	if err = pprof.StartCPUProfile(f); err != nil {
+		f.Close()
+		return err
+	}
+
+	cpuProfile = f
+	return nil
+}
+
+// stopProfiling Flushes the CPU profile and writes the heap profile, if they
+// were requested. This runs after the command, even if it failed, and does
+// nothing if the profiles were already stopped.
+func stopProfiling() error {
+	if cpuProfile != nil {
+		pprof.StopCPUProfile()
+		err := cpuProfile.Close()
+		cpuProfile = nil
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(memProfile) == 0 {
+		return nil
+	}
+
+	f, err := os.Create(memProfile)
+	memProfile = ""
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	// Get up-to-date statistics.
+	runtime.GC()
+	return pprof.WriteHeapProfile(f)
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/godarch/darch/pkg/utils"
+	"github.com/urfave/cli"
+)
+
+func TestProfilingStoppedOnExitError(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"command", []string{"fail"}},
+		{"subcommand", []string{"recipes", "fail"}},
+	}
+
+	for _, test := range tests {
+		profile := path.Join(os.TempDir(), utils.NewID())
+		defer os.Remove(profile)
+
+		// The real exiter doesn't return, so check the profile when it's called.
+		exitCode, stopped := -1, false
+		cli.OsExiter = func(code int) { exitCode, stopped = code, cpuProfile == nil }
+
+		fail := cli.Command{
+			Name: "fail",
+			Action: func(c *cli.Context) error {
+				return cli.NewExitError("failed", 3)
+			},
+		}
+
+		app := cli.NewApp()
+		app.Writer = ioutil.Discard
+		cli.ErrWriter = ioutil.Discard
+		configureProfiling(app)
+		app.Commands = []cli.Command{
+			fail,
+			{Name: "recipes", Subcommands: []cli.Command{fail}},
+		}
+
+		app.Run(append([]string{"darch", "--cpuprofile", profile}, test.args...))
+
+		cli.OsExiter = os.Exit
+		cli.ErrWriter = os.Stderr
+
+		if exitCode != 3 {
+			t.Fatalf("%s: expected exit code 3, got %d", test.name, exitCode)
+		}
+		if !stopped {
+			t.Fatalf("%s: expected the CPU profile to be stopped before exiting", test.name)
+		}
+		if stat, err := os.Stat(profile); err != nil || stat.Size() == 0 {
+			t.Fatalf("%s: expected the CPU profile to be flushed, got %v", test.name, err)
+		}
+	}
+}
+
+func TestStopProfilingTwice(t *testing.T) {
+	profile := path.Join(os.TempDir(), utils.NewID())
+	defer os.Remove(profile)
+
+	memProfile = profile
+	if err := stopProfiling(); err != nil {
+		t.Fatalf("error stopping profiling %v", err)
+	}
+	if err := os.Remove(profile); err != nil {
+		t.Fatalf("expected the heap profile to be written, got %v", err)
+	}
+
+	if err := stopProfiling(); err != nil {
+		t.Fatalf("error stopping profiling %v", err)
+	}
+	if _, err := os.Stat(profile); !os.IsNotExist(err) {
+		t.Fatal("expected the heap profile to only be written once")
+	}
+}
@@ -0,0 +1,81 @@
+package darchrc
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/godarch/darch/pkg/utils"
+)
+
+// Settings Default flag values read from a darchrc file, keyed by flag name.
+//
+// Values are resolved in the following order of precedence:
+//  1. the flag, when given explicitly on the command line
+//  2. the environment variable, DARCH_ followed by the flag name in upper
+//     case with dashes replaced by underscores (recipes-dir is DARCH_RECIPES_DIR)
+//  3. the darchrc file
+//  4. the flag's built-in default
+type Settings map[string]string
+
+// DefaultLocation Returns the location of the darchrc file, ~/.darchrc.
+func DefaultLocation() string {
+	return path.Join(os.Getenv("HOME"), ".darchrc")
+}
+
+// Load Reads settings from a file of key=value lines. Blank lines and lines
+// starting with # are ignored. A missing file results in empty settings.
+func Load(file string) (Settings, error) {
+	settings := Settings{}
+
+	if !utils.FileExists(file) {
+		return settings, nil
+	}
+
+	lines, err := utils.GetFileLines(file)
+	if err != nil {
+		return settings, err
+	}
+
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		split := strings.SplitN(line, "=", 2)
+		if len(split) != 2 || len(strings.TrimSpace(split[0])) == 0 {
+			return settings, fmt.Errorf("invalid setting at %s:%d, expected key=value", file, i+1)
+		}
+		settings[strings.TrimSpace(split[0])] = strings.TrimSpace(split[1])
+	}
+
+	return settings, nil
+}
+
+// EnvVar Returns the name of the environment variable for a flag.
+func EnvVar(name string) string {
+	return "DARCH_" + strings.ToUpper(strings.Replace(name, "-", "_", -1))
+}
+
+// Lookup Returns the value of a flag, following the order of precedence
+// described on Settings. flagValue is the flag's value, which is its
+// default when flagSet is false.
+func (settings Settings) Lookup(name string, flagSet bool, flagValue string) string {
+	return settings.LookupWithEnv(name, flagSet, flagValue, os.LookupEnv)
+}
+
+// LookupWithEnv Same as Lookup, looking up the environment variable with
+// lookupEnv instead of in the environment.
+func (settings Settings) LookupWithEnv(name string, flagSet bool, flagValue string, lookupEnv func(string) (string, bool)) string {
+	if flagSet {
+		return flagValue
+	}
+	if value, ok := lookupEnv(EnvVar(name)); ok {
+		return value
+	}
+	if value, ok := settings[name]; ok {
+		return value
+	}
+	return flagValue
+}
@@ -0,0 +1,83 @@
+package darchrc
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/godarch/darch/pkg/utils"
+)
+
+func TestLoad(t *testing.T) {
+	file := path.Join(os.TempDir(), utils.NewID())
+	defer os.RemoveAll(file)
+
+	err := ioutil.WriteFile(file, []byte("# comment\n\nrecipes-dir = ~/recipes\nconcurrency=2\n"), 0644)
+	if err != nil {
+		t.Fatalf("error writing file %v", err)
+	}
+
+	settings, err := Load(file)
+	if err != nil {
+		t.Fatalf("error loading settings %v", err)
+	}
+
+	if settings["recipes-dir"] != "~/recipes" || settings["concurrency"] != "2" || len(settings) != 2 {
+		t.Fatalf("unexpected settings %v", settings)
+	}
+}
+
+func TestLoadMissing(t *testing.T) {
+	settings, err := Load(path.Join(os.TempDir(), utils.NewID()))
+	if err != nil {
+		t.Fatalf("error loading settings %v", err)
+	}
+
+	if len(settings) != 0 {
+		t.Fatalf("unexpected settings %v", settings)
+	}
+}
+
+func TestLookupPrecedence(t *testing.T) {
+	os.Unsetenv("DARCH_TEST_SETTING")
+	defer os.Unsetenv("DARCH_TEST_SETTING")
+
+	settings := Settings{}
+
+	if value := settings.Lookup("test-setting", false, "default"); value != "default" {
+		t.Fatalf("expected the default, got %s", value)
+	}
+
+	settings["test-setting"] = "dotfile"
+	if value := settings.Lookup("test-setting", false, "default"); value != "dotfile" {
+		t.Fatalf("expected the dotfile value, got %s", value)
+	}
+
+	os.Setenv("DARCH_TEST_SETTING", "env")
+	if value := settings.Lookup("test-setting", false, "default"); value != "env" {
+		t.Fatalf("expected the environment value, got %s", value)
+	}
+
+	if value := settings.Lookup("test-setting", true, "flag"); value != "flag" {
+		t.Fatalf("expected the flag value, got %s", value)
+	}
+}
+
+func TestLookupWithEnv(t *testing.T) {
+	settings := Settings{"test-setting": "dotfile"}
+	lookupEnv := func(name string) (string, bool) {
+		if name == "DARCH_TEST_SETTING" {
+			return "env", true
+		}
+		return "", false
+	}
+
+	if value := settings.LookupWithEnv("test-setting", false, "default", lookupEnv); value != "env" {
+		t.Fatalf("expected env, got %s", value)
+	}
+
+	if value := settings.LookupWithEnv("other-setting", false, "default", lookupEnv); value != "default" {
+		t.Fatalf("expected default, got %s", value)
+	}
+}
@@ -0,0 +1,90 @@
+package recipes
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/gobwas/glob"
+)
+
+// ParseBundle Parses recipes from a bundle, a JSON object with the
+// config.json configuration of each recipe keyed by the recipe's name:
+//
+//	{"base": {"inherits": "external:archlinux/base"}, "web": {"inherits": "base"}}
+//
+// Recipes from a bundle have no directories, so they can be inspected but
+// not built. Like ParseAllRecipes, the recipes aren't validated, and only
+// the recipes matching options.Only are kept, along with their parents.
+func ParseBundle(r io.Reader, options LoadOptions) (map[string]Recipe, error) {
+	configurations := make(map[string]recipeConfiguration, 0)
+	if err := json.NewDecoder(r).Decode(&configurations); err != nil {
+		return nil, fmt.Errorf("invalid recipe bundle: %v", err)
+	}
+
+	names := make([]string, 0, len(configurations))
+	for name := range configurations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if !options.AllowCaseCollision {
+		if collisions := CaseCollisions(names); len(collisions) > 0 {
+			return nil, fmt.Errorf("recipes %s differ only in case, which is ambiguous", strings.Join(collisions[0], ", "))
+		}
+	}
+
+	recipes := make(map[string]Recipe, len(configurations))
+	for _, name := range names {
+		if len(name) == 0 {
+			return nil, fmt.Errorf("A recipe name must be provided")
+		}
+		configuration := configurations[name]
+		if err := checkRecipeConfiguration(name, &configuration, options); err != nil {
+			return nil, err
+		}
+		recipe := Recipe{Name: name}
+		applyRecipeConfiguration(&recipe, configuration)
+		recipes[name] = recipe
+	}
+
+	if len(options.Only) > 0 {
+		return filterOnly(recipes, options.Only)
+	}
+
+	return recipes, nil
+}
+
+// filterOnly Returns the recipes whose name matches the glob, along with
+// their parents.
+func filterOnly(recipes map[string]Recipe, only string) (map[string]Recipe, error) {
+	g, err := glob.Compile(only)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]Recipe, 0)
+	for name, recipe := range recipes {
+		if !g.Match(name) {
+			continue
+		}
+		for {
+			if _, ok := result[recipe.Name]; ok {
+				break
+			}
+			result[recipe.Name] = recipe
+			if recipe.InheritsExternal {
+				break
+			}
+			parent, ok := recipes[recipe.Inherits]
+			if !ok {
+				break
+			}
+			recipe = parent
+		}
+	}
+
+	return result, nil
+}
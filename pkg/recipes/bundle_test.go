@@ -0,0 +1,59 @@
+package recipes
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestParseBundle(t *testing.T) {
+	rs, err := ParseBundle(strings.NewReader(`{
+		"base": {"inherits": "external:archlinux/base"},
+		"web": {"inherits": "base", "buildArgs": {"PORT": "8080"}}
+	}`), LoadOptions{})
+	if err != nil {
+		t.Fatalf("error parsing bundle %v", err)
+	}
+
+	if len(rs) != 2 || !rs["base"].InheritsExternal || rs["web"].Inherits != "base" || rs["web"].BuildArgs["PORT"] != "8080" {
+		t.Fatalf("unexpected recipes %v", rs)
+	}
+}
+
+func TestParseBundleInvalid(t *testing.T) {
+	bundles := []string{
+		`not json`,
+		`["base"]`,
+		`{"base": {}}`,
+		`{"": {"inherits": "external:archlinux/base"}}`,
+	}
+
+	for _, bundle := range bundles {
+		if _, err := ParseBundle(strings.NewReader(bundle), LoadOptions{}); err == nil {
+			t.Fatalf("expected an error for %s", bundle)
+		}
+	}
+}
+
+func TestParseBundleOnly(t *testing.T) {
+	rs, err := ParseBundle(strings.NewReader(`{
+		"base": {"inherits": "external:archlinux/base"},
+		"web": {"inherits": "base"},
+		"web-dev": {"inherits": "web"},
+		"desktop": {"inherits": "base"}
+	}`), LoadOptions{Only: "web-*"})
+	if err != nil {
+		t.Fatalf("error parsing bundle %v", err)
+	}
+
+	names := make([]string, 0)
+	for name := range rs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if !reflect.DeepEqual(names, []string{"base", "web", "web-dev"}) {
+		t.Fatalf("expected web-dev and its parents, got %v", names)
+	}
+}
@@ -0,0 +1,217 @@
+package recipes
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ResolveChain Returns the recipe followed by each of its parents, ending
+// with the recipe that inherits the external image.
+func ResolveChain(recipe Recipe, recipes map[string]Recipe) ([]Recipe, error) {
+	chain := []Recipe{recipe}
+	visited := map[string]bool{recipe.Name: true}
+
+	current := recipe
+	for !current.InheritsExternal {
+		parent, ok := recipes[current.Inherits]
+		if !ok {
+			return nil, fmt.Errorf("recipe %s inherits from %s, which doesn't exist", current.Name, current.Inherits)
+		}
+		if visited[parent.Name] {
+			names := make([]string, 0, len(chain)+1)
+			for _, r := range chain {
+				names = append(names, r.Name)
+			}
+			return nil, fmt.Errorf("cycle detected: %s -> %s", strings.Join(names, " -> "), parent.Name)
+		}
+		visited[parent.Name] = true
+		chain = append(chain, parent)
+		current = parent
+	}
+
+	return chain, nil
+}
+
+// ChainHash Returns a SHA-256 hash of the recipe's inheritance chain.
+// The hash is computed over the external image the chain is rooted on,
+// followed by the name and the sorted build args of each recipe from the
+// root down to the given recipe. Recipes with identical chains will produce
+// identical hashes, and changing the external image, or the name or build
+// args of an ancestor, will change the hash.
+func ChainHash(recipe Recipe, recipes map[string]Recipe) (string, error) {
+	chain, err := ResolveChain(recipe, recipes)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.New()
+	fmt.Fprintf(hash, "external:%s\n", chain[len(chain)-1].Inherits)
+	for i := len(chain) - 1; i >= 0; i-- {
+		fmt.Fprintf(hash, "recipe:%s\n", chain[i].Name)
+		keys := make([]string, 0, len(chain[i].BuildArgs))
+		for key := range chain[i].BuildArgs {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Fprintf(hash, "arg:%s=%s\n", key, chain[i].BuildArgs[key])
+		}
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// ChainHashes Returns the ChainHash of every recipe, keyed by recipe name.
+func ChainHashes(recipes map[string]Recipe) (map[string]string, error) {
+	result := make(map[string]string, len(recipes))
+	for _, recipe := range recipes {
+		hash, err := ChainHash(recipe, recipes)
+		if err != nil {
+			return nil, err
+		}
+		result[recipe.Name] = hash
+	}
+	return result, nil
+}
+
+// ChangedChainHashes Returns the sorted names of the recipes in current whose
+// hash differs from the one in previous, or which aren't in previous at all.
+func ChangedChainHashes(previous map[string]string, current map[string]string) []string {
+	result := make([]string, 0)
+	for name, hash := range current {
+		if previousHash, ok := previous[name]; !ok || previousHash != hash {
+			result = append(result, name)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Distance Returns the number of inheritance hops from the recipe up to the
+// given ancestor, which may be a recipe or the external image the chain is
+// rooted on. Returns -1 if the ancestor isn't in the recipe's chain.
+func Distance(recipe Recipe, ancestor string, recipes map[string]Recipe) (int, error) {
+	chain, err := ResolveChain(recipe, recipes)
+	if err != nil {
+		return -1, err
+	}
+
+	for i, r := range chain {
+		if r.Name == ancestor {
+			return i, nil
+		}
+	}
+
+	if chain[len(chain)-1].Inherits == ancestor {
+		return len(chain), nil
+	}
+
+	return -1, nil
+}
+
+// BuildArgOrigin Returns the recipe in the chain whose value for the build
+// arg wins, which is the nearest one to the given recipe that sets it.
+// Returns false if no recipe in the chain sets the build arg.
+func BuildArgOrigin(recipe Recipe, key string, recipes map[string]Recipe) (Recipe, bool, error) {
+	chain, err := ResolveChain(recipe, recipes)
+	if err != nil {
+		return Recipe{}, false, err
+	}
+
+	for _, r := range chain {
+		if _, ok := r.BuildArgs[key]; ok {
+			return r, true, nil
+		}
+	}
+
+	return Recipe{}, false, nil
+}
+
+// EffectiveBuildArgs Returns the build args the recipe is built with,
+// merged from its chain, where a recipe's values override its parents'.
+func EffectiveBuildArgs(recipe Recipe, recipes map[string]Recipe) (map[string]string, error) {
+	chain, err := ResolveChain(recipe, recipes)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]string, 0)
+	for i := len(chain) - 1; i >= 0; i-- {
+		for key, value := range chain[i].BuildArgs {
+			result[key] = value
+		}
+	}
+
+	return result, nil
+}
+
+// LongestChain Returns the longest chain of all the recipes, as returned by
+// ResolveChain, so its length is the distance from the external image down
+// to the deepest recipe. When chains are the same length, the one ending
+// with the first recipe by name is returned. Returns nil if there are no
+// recipes.
+func LongestChain(recipes map[string]Recipe) ([]Recipe, error) {
+	names := make([]string, 0, len(recipes))
+	for name := range recipes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var longest []Recipe
+	for _, name := range names {
+		chain, err := ResolveChain(recipes[name], recipes)
+		if err != nil {
+			return nil, err
+		}
+		if len(chain) > len(longest) {
+			longest = chain
+		}
+	}
+
+	return longest, nil
+}
+
+// CommonChainPrefix Returns the external image and the recipes shared by
+// the start of the chains of all the given recipes, from the recipe
+// inheriting the external image downward. A recipe that is an ancestor of
+// all the others is included. Recipes built directly on the same external
+// image share it, even when they share no recipes, so the prefix can be
+// empty. Returns an error if the recipes are built on different external
+// images.
+func CommonChainPrefix(selected []Recipe, recipes map[string]Recipe) (string, []Recipe, error) {
+	var (
+		external string
+		prefix   []Recipe
+	)
+	for i, recipe := range selected {
+		chain, err := ResolveChain(recipe, recipes)
+		if err != nil {
+			return "", nil, err
+		}
+		// Root first, so the shared part is at the start.
+		for left, right := 0, len(chain)-1; left < right; left, right = left+1, right-1 {
+			chain[left], chain[right] = chain[right], chain[left]
+		}
+		if i == 0 {
+			external = chain[0].Inherits
+			prefix = chain
+			continue
+		}
+		if chain[0].Inherits != external {
+			return "", nil, fmt.Errorf("recipes %s and %s are built on different external images, %s and %s", selected[0].Name, recipe.Name, external, chain[0].Inherits)
+		}
+		length := 0
+		for length < len(prefix) && length < len(chain) && prefix[length].Name == chain[length].Name {
+			length++
+		}
+		prefix = prefix[:length]
+	}
+
+	if prefix == nil {
+		prefix = make([]Recipe, 0)
+	}
+	return external, prefix, nil
+}
@@ -0,0 +1,263 @@
+package recipes
+
+import (
+	"reflect"
+	"testing"
+)
+
+func testRecipes() map[string]Recipe {
+	return map[string]Recipe{
+		"base":        {Name: "base", Inherits: "archlinux/base", InheritsExternal: true},
+		"base-common": {Name: "base-common", Inherits: "base"},
+		"desktop":     {Name: "desktop", Inherits: "base-common"},
+		"server":      {Name: "server", Inherits: "base-common"},
+		"web":         {Name: "web", Inherits: "server"},
+		"tools":       {Name: "tools", Inherits: "ubuntu:20.04", InheritsExternal: true},
+		"tools-extra": {Name: "tools-extra", Inherits: "tools"},
+	}
+}
+
+func TestResolveChain(t *testing.T) {
+	rs := testRecipes()
+
+	chain, err := ResolveChain(rs["web"], rs)
+	if err != nil {
+		t.Fatalf("error resolving chain %v", err)
+	}
+
+	names := make([]string, 0)
+	for _, r := range chain {
+		names = append(names, r.Name)
+	}
+
+	expected := []string{"web", "server", "base-common", "base"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Fatalf("expected %v, got %v", expected, names)
+	}
+}
+
+func TestResolveChainCycle(t *testing.T) {
+	tests := map[string]struct {
+		recipes  map[string]Recipe
+		expected string
+	}{
+		"two recipes": {
+			recipes: map[string]Recipe{
+				"a": {Name: "a", Inherits: "b"},
+				"b": {Name: "b", Inherits: "a"},
+			},
+			expected: "cycle detected: a -> b -> a",
+		},
+		"three recipes": {
+			recipes: map[string]Recipe{
+				"a": {Name: "a", Inherits: "b"},
+				"b": {Name: "b", Inherits: "c"},
+				"c": {Name: "c", Inherits: "a"},
+			},
+			expected: "cycle detected: a -> b -> c -> a",
+		},
+	}
+
+	for name, test := range tests {
+		_, err := ResolveChain(test.recipes["a"], test.recipes)
+		if err == nil || err.Error() != test.expected {
+			t.Fatalf("%s: expected %q, got %v", name, test.expected, err)
+		}
+	}
+}
+
+func TestChainHashStable(t *testing.T) {
+	first, err := ChainHashes(testRecipes())
+	if err != nil {
+		t.Fatalf("error computing hashes %v", err)
+	}
+
+	second, err := ChainHashes(testRecipes())
+	if err != nil {
+		t.Fatalf("error computing hashes %v", err)
+	}
+
+	if changed := ChangedChainHashes(first, second); len(changed) != 0 {
+		t.Fatalf("expected no changes, got %v", changed)
+	}
+}
+
+func TestChainHashChangesDescendants(t *testing.T) {
+	rs := testRecipes()
+
+	previous, err := ChainHashes(rs)
+	if err != nil {
+		t.Fatalf("error computing hashes %v", err)
+	}
+
+	base := rs["base"]
+	base.Inherits = "archlinux/base:2018.01.01"
+	rs["base"] = base
+
+	current, err := ChainHashes(rs)
+	if err != nil {
+		t.Fatalf("error computing hashes %v", err)
+	}
+
+	changed := ChangedChainHashes(previous, current)
+	expected := []string{"base", "base-common", "desktop", "server", "web"}
+	if !reflect.DeepEqual(changed, expected) {
+		t.Fatalf("expected %v, got %v", expected, changed)
+	}
+}
+
+func TestChainHashChangesBuildArgs(t *testing.T) {
+	rs := testRecipes()
+	base := rs["base"]
+	base.BuildArgs = map[string]string{"LOCALE": "en_US"}
+	rs["base"] = base
+
+	previous, err := ChainHashes(rs)
+	if err != nil {
+		t.Fatalf("error computing hashes %v", err)
+	}
+
+	base.BuildArgs = map[string]string{"LOCALE": "de_DE"}
+	rs["base"] = base
+
+	current, err := ChainHashes(rs)
+	if err != nil {
+		t.Fatalf("error computing hashes %v", err)
+	}
+
+	changed := ChangedChainHashes(previous, current)
+	expected := []string{"base", "base-common", "desktop", "server", "web"}
+	if !reflect.DeepEqual(changed, expected) {
+		t.Fatalf("expected %v, got %v", expected, changed)
+	}
+}
+
+func TestDistance(t *testing.T) {
+	rs := testRecipes()
+
+	tests := map[string]int{
+		"web":            0,
+		"server":         1,
+		"base":           3,
+		"archlinux/base": 4,
+		"desktop":        -1,
+	}
+
+	for ancestor, expected := range tests {
+		distance, err := Distance(rs["web"], ancestor, rs)
+		if err != nil {
+			t.Fatalf("error computing distance %v", err)
+		}
+		if distance != expected {
+			t.Fatalf("expected distance to %s to be %d, got %d", ancestor, expected, distance)
+		}
+	}
+}
+
+func TestBuildArgOrigin(t *testing.T) {
+	rs := testRecipes()
+	rs["base"] = Recipe{Name: "base", Inherits: "archlinux/base", InheritsExternal: true, BuildArgs: map[string]string{"NODE_VERSION": "16", "LOCALE": "en_US"}}
+	rs["server"] = Recipe{Name: "server", Inherits: "base-common", BuildArgs: map[string]string{"NODE_VERSION": "18"}}
+
+	origin, ok, err := BuildArgOrigin(rs["web"], "NODE_VERSION", rs)
+	if err != nil {
+		t.Fatalf("error finding build arg origin %v", err)
+	}
+	if !ok || origin.Name != "server" {
+		t.Fatalf("expected server, got %v", origin)
+	}
+
+	origin, ok, err = BuildArgOrigin(rs["web"], "LOCALE", rs)
+	if err != nil {
+		t.Fatalf("error finding build arg origin %v", err)
+	}
+	if !ok || origin.Name != "base" {
+		t.Fatalf("expected base, got %v", origin)
+	}
+
+	_, ok, err = BuildArgOrigin(rs["web"], "MISSING", rs)
+	if err != nil {
+		t.Fatalf("error finding build arg origin %v", err)
+	}
+	if ok {
+		t.Fatal("expected no origin for a build arg that isn't set")
+	}
+}
+
+func TestEffectiveBuildArgs(t *testing.T) {
+	rs := testRecipes()
+	rs["base"] = Recipe{Name: "base", Inherits: "archlinux/base", InheritsExternal: true, BuildArgs: map[string]string{"NODE_VERSION": "16", "LOCALE": "en_US"}}
+	rs["server"] = Recipe{Name: "server", Inherits: "base-common", BuildArgs: map[string]string{"NODE_VERSION": "18"}}
+
+	buildArgs, err := EffectiveBuildArgs(rs["web"], rs)
+	if err != nil {
+		t.Fatalf("error merging build args %v", err)
+	}
+
+	expected := map[string]string{"NODE_VERSION": "18", "LOCALE": "en_US"}
+	if !reflect.DeepEqual(buildArgs, expected) {
+		t.Fatalf("expected %v, got %v", expected, buildArgs)
+	}
+}
+
+func TestLongestChain(t *testing.T) {
+	chain, err := LongestChain(testRecipes())
+	if err != nil {
+		t.Fatalf("error finding longest chain %v", err)
+	}
+
+	names := make([]string, 0)
+	for _, r := range chain {
+		names = append(names, r.Name)
+	}
+
+	if !reflect.DeepEqual(names, []string{"web", "server", "base-common", "base"}) {
+		t.Fatalf("unexpected chain %v", names)
+	}
+
+	if chain, err = LongestChain(map[string]Recipe{}); err != nil || chain != nil {
+		t.Fatalf("expected no chain, got %v %v", chain, err)
+	}
+}
+
+func TestCommonChainPrefix(t *testing.T) {
+	rs := testRecipes()
+	rs["minimal"] = Recipe{Name: "minimal", Inherits: "archlinux/base", InheritsExternal: true}
+
+	tests := []struct {
+		recipes  []string
+		expected []string
+	}{
+		{[]string{"web", "desktop"}, []string{"base", "base-common"}},
+		{[]string{"web", "server"}, []string{"base", "base-common", "server"}},
+		{[]string{"web", "desktop", "base"}, []string{"base"}},
+		{[]string{"web", "minimal"}, []string{}},
+	}
+
+	for _, test := range tests {
+		selected := make([]Recipe, 0)
+		for _, name := range test.recipes {
+			selected = append(selected, rs[name])
+		}
+
+		external, prefix, err := CommonChainPrefix(selected, rs)
+		if err != nil {
+			t.Fatalf("error finding common prefix %v", err)
+		}
+		if external != "archlinux/base" {
+			t.Fatalf("expected archlinux/base for %v, got %s", test.recipes, external)
+		}
+
+		names := make([]string, 0)
+		for _, r := range prefix {
+			names = append(names, r.Name)
+		}
+		if !reflect.DeepEqual(names, test.expected) {
+			t.Fatalf("expected %v for %v, got %v", test.expected, test.recipes, names)
+		}
+	}
+
+	if _, _, err := CommonChainPrefix([]Recipe{rs["web"], rs["tools-extra"]}, rs); err == nil {
+		t.Fatal("expected an error for recipes on different external images")
+	}
+}
@@ -0,0 +1,284 @@
+package recipes
+
+import (
+	"errors"
+	"sort"
+)
+
+// DirectChildren Returns the sorted names of the recipes that directly
+// inherit each recipe, keyed by the parent's name. Recipes without
+// children are not included.
+func DirectChildren(recipes map[string]Recipe) map[string][]string {
+	result := make(map[string][]string, 0)
+	for _, recipe := range recipes {
+		if !recipe.InheritsExternal {
+			result[recipe.Inherits] = append(result[recipe.Inherits], recipe.Name)
+		}
+	}
+	for _, children := range result {
+		sort.Strings(children)
+	}
+	return result
+}
+
+// ExternalBases Returns the sorted names of the recipes that directly
+// inherit each external image, keyed by the external image. Only direct
+// dependents are included, not the recipes inheriting from them.
+func ExternalBases(recipes map[string]Recipe) map[string][]string {
+	result := make(map[string][]string, 0)
+	for _, recipe := range recipes {
+		if recipe.InheritsExternal {
+			result[recipe.Inherits] = append(result[recipe.Inherits], recipe.Name)
+		}
+	}
+	for _, dependents := range result {
+		sort.Strings(dependents)
+	}
+	return result
+}
+
+// Descendants Returns the names of the recipes inheriting from the given
+// recipe, up to maxDepth levels down (a negative maxDepth is unlimited).
+// The result is ordered breadth first, and sorted within each level.
+func Descendants(recipe Recipe, recipes map[string]Recipe, maxDepth int) []string {
+	children := DirectChildren(recipes)
+
+	result := make([]string, 0)
+	visited := map[string]bool{recipe.Name: true}
+	level := []string{recipe.Name}
+
+	for depth := 0; len(level) > 0 && (maxDepth < 0 || depth < maxDepth); depth++ {
+		next := make([]string, 0)
+		for _, name := range level {
+			for _, child := range children[name] {
+				if !visited[child] {
+					visited[child] = true
+					next = append(next, child)
+				}
+			}
+		}
+		sort.Strings(next)
+		result = append(result, next...)
+		level = next
+	}
+
+	return result
+}
+
+// Nearby Returns the sorted names of the recipes within radius inheritance
+// hops of the given recipe, not including it. Hops are counted in either
+// direction, so a sibling is 2 hops away, through their parent.
+func Nearby(recipe Recipe, recipes map[string]Recipe, radius int) []string {
+	children := DirectChildren(recipes)
+
+	result := make([]string, 0)
+	visited := map[string]bool{recipe.Name: true}
+	level := []string{recipe.Name}
+
+	for hops := 0; len(level) > 0 && hops < radius; hops++ {
+		next := make([]string, 0)
+		for _, name := range level {
+			neighbours := children[name]
+			if r, ok := recipes[name]; ok && !r.InheritsExternal {
+				neighbours = append([]string{r.Inherits}, neighbours...)
+			}
+			for _, neighbour := range neighbours {
+				if _, ok := recipes[neighbour]; ok && !visited[neighbour] {
+					visited[neighbour] = true
+					next = append(next, neighbour)
+				}
+			}
+		}
+		result = append(result, next...)
+		level = next
+	}
+
+	sort.Strings(result)
+	return result
+}
+
+// AllDescendants Returns the sorted names of all the recipes inheriting
+// from each recipe, directly or not, keyed by the recipe's name. Each
+// subtree is only walked once, so this is much cheaper than calling
+// Descendants for every recipe. The recipes should be validated first, as
+// recipes in a cycle won't have complete results.
+func AllDescendants(recipes map[string]Recipe) map[string][]string {
+	children := DirectChildren(recipes)
+
+	result := make(map[string][]string, len(recipes))
+	visiting := make(map[string]bool, 0)
+
+	var walk func(name string) []string
+	walk = func(name string) []string {
+		if descendants, ok := result[name]; ok {
+			return descendants
+		}
+		if visiting[name] {
+			return nil
+		}
+		visiting[name] = true
+
+		descendants := make([]string, 0)
+		for _, child := range children[name] {
+			descendants = append(descendants, child)
+			descendants = append(descendants, walk(child)...)
+		}
+		sort.Strings(descendants)
+
+		result[name] = descendants
+		return descendants
+	}
+
+	for name := range recipes {
+		walk(name)
+	}
+
+	return result
+}
+
+// Leaves Returns the sorted names of the recipes that no other recipe inherits.
+func Leaves(recipes map[string]Recipe) []string {
+	children := DirectChildren(recipes)
+	result := make([]string, 0)
+	for _, recipe := range recipes {
+		if _, ok := children[recipe.Name]; !ok {
+			result = append(result, recipe.Name)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// BuildOrder Returns the names of all the recipes, ordered so that every
+// recipe comes after the recipe it inherits. Recipes that can be built at
+// the same point are sorted by name. Returns an error naming the problem
+// if the recipes have a cycle or a missing parent.
+func BuildOrder(recipes map[string]Recipe) ([]string, error) {
+	if problems := Validate(recipes); len(problems) > 0 {
+		return nil, errors.New(problems[0].Message)
+	}
+
+	children := DirectChildren(recipes)
+
+	ready := make([]string, 0)
+	for _, recipe := range recipes {
+		if recipe.InheritsExternal {
+			ready = append(ready, recipe.Name)
+		}
+	}
+
+	result := make([]string, 0, len(recipes))
+	for len(ready) > 0 {
+		sort.Strings(ready)
+		current := ready[0]
+		ready = append(ready[1:], children[current]...)
+		result = append(result, current)
+	}
+
+	return result, nil
+}
+
+// BuildWaves Groups the names of all the recipes into waves, where every
+// recipe in a wave only depends on recipes in earlier waves, so each wave
+// can be built in parallel. Each wave is sorted by name. Returns an error
+// naming the problem if the recipes have a cycle or a missing parent.
+func BuildWaves(recipes map[string]Recipe) ([][]string, error) {
+	if problems := Validate(recipes); len(problems) > 0 {
+		return nil, errors.New(problems[0].Message)
+	}
+
+	children := DirectChildren(recipes)
+
+	wave := make([]string, 0)
+	for _, recipe := range recipes {
+		if recipe.InheritsExternal {
+			wave = append(wave, recipe.Name)
+		}
+	}
+
+	result := make([][]string, 0)
+	for len(wave) > 0 {
+		sort.Strings(wave)
+		result = append(result, wave)
+		next := make([]string, 0)
+		for _, name := range wave {
+			next = append(next, children[name]...)
+		}
+		wave = next
+	}
+
+	return result, nil
+}
+
+// BuildEstimate An estimate of how long building a set of recipes takes,
+// from their BuildSeconds hints.
+type BuildEstimate struct {
+	// CriticalPathSeconds The sum of the slowest recipe in each wave, which
+	// is how long the build takes when each wave is built in parallel.
+	CriticalPathSeconds int
+	// SerialSeconds The sum of every recipe, building one at a time.
+	SerialSeconds int
+	// Unknown The sorted names of the recipes without a hint. They are
+	// counted as taking no time, so the estimate is a lower bound.
+	Unknown []string
+}
+
+// EstimateBuildTime Estimates how long building the waves of recipes takes,
+// as returned by BuildWaves.
+func EstimateBuildTime(waves [][]string, recipes map[string]Recipe) BuildEstimate {
+	estimate := BuildEstimate{Unknown: make([]string, 0)}
+	for _, wave := range waves {
+		slowest := 0
+		for _, name := range wave {
+			seconds := recipes[name].BuildSeconds
+			if seconds == 0 {
+				estimate.Unknown = append(estimate.Unknown, name)
+			}
+			if seconds > slowest {
+				slowest = seconds
+			}
+			estimate.SerialSeconds += seconds
+		}
+		estimate.CriticalPathSeconds += slowest
+	}
+	sort.Strings(estimate.Unknown)
+	return estimate
+}
+
+// Partitions Groups the names of all the recipes into sets that don't
+// depend on each other, so each set can be built independently. Every
+// recipe is grouped with the recipe at the root of its chain. When
+// joinExternals is set, roots inheriting the same external image are also
+// grouped together. Each partition is sorted, and the partitions are
+// ordered by their first name. Returns an error naming the problem if the
+// recipes have a cycle or a missing parent.
+func Partitions(recipes map[string]Recipe, joinExternals bool) ([][]string, error) {
+	if problems := Validate(recipes); len(problems) > 0 {
+		return nil, errors.New(problems[0].Message)
+	}
+
+	groups := make(map[string][]string, 0)
+	for _, recipe := range recipes {
+		chain, err := ResolveChain(recipe, recipes)
+		if err != nil {
+			return nil, err
+		}
+		root := chain[len(chain)-1]
+		key := "recipe:" + root.Name
+		if joinExternals {
+			key = "external:" + root.Inherits
+		}
+		groups[key] = append(groups[key], recipe.Name)
+	}
+
+	result := make([][]string, 0, len(groups))
+	for _, group := range groups {
+		sort.Strings(group)
+		result = append(result, group)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i][0] < result[j][0]
+	})
+
+	return result, nil
+}
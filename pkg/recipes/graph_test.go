@@ -0,0 +1,205 @@
+package recipes
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestDirectChildren(t *testing.T) {
+	children := DirectChildren(testRecipes())
+
+	if !reflect.DeepEqual(children["base-common"], []string{"desktop", "server"}) {
+		t.Fatalf("unexpected children %v", children["base-common"])
+	}
+
+	if _, ok := children["web"]; ok {
+		t.Fatal("leaf recipes shouldn't have children")
+	}
+}
+
+func TestExternalBases(t *testing.T) {
+	rs := testRecipes()
+	rs["other"] = Recipe{Name: "other", Inherits: "archlinux/base", InheritsExternal: true}
+
+	bases := ExternalBases(rs)
+
+	expected := map[string][]string{
+		"archlinux/base": {"base", "other"},
+		"ubuntu:20.04":   {"tools"},
+	}
+	if !reflect.DeepEqual(bases, expected) {
+		t.Fatalf("expected %v, got %v", expected, bases)
+	}
+}
+
+func TestDescendants(t *testing.T) {
+	rs := testRecipes()
+
+	all := Descendants(rs["base"], rs, -1)
+	if !reflect.DeepEqual(all, []string{"base-common", "desktop", "server", "web"}) {
+		t.Fatalf("unexpected descendants %v", all)
+	}
+
+	limited := Descendants(rs["base"], rs, 2)
+	if !reflect.DeepEqual(limited, []string{"base-common", "desktop", "server"}) {
+		t.Fatalf("unexpected descendants %v", limited)
+	}
+}
+
+func TestAllDescendants(t *testing.T) {
+	rs := testRecipes()
+	all := AllDescendants(rs)
+
+	if len(all) != len(rs) {
+		t.Fatalf("expected a result for all %d recipes, got %d", len(rs), len(all))
+	}
+
+	if !reflect.DeepEqual(all["base"], []string{"base-common", "desktop", "server", "web"}) {
+		t.Fatalf("unexpected descendants %v", all["base"])
+	}
+
+	if len(all["web"]) != 0 {
+		t.Fatalf("expected no descendants for a leaf, got %v", all["web"])
+	}
+}
+
+// benchmarkRecipes Builds depth levels of width recipes, each inheriting from a recipe in the level above.
+func benchmarkRecipes(depth int, width int) map[string]Recipe {
+	rs := make(map[string]Recipe, depth*width)
+	for level := 0; level < depth; level++ {
+		for i := 0; i < width; i++ {
+			name := fmt.Sprintf("recipe-%d-%d", level, i)
+			if level == 0 {
+				rs[name] = Recipe{Name: name, Inherits: "archlinux/base", InheritsExternal: true}
+			} else {
+				rs[name] = Recipe{Name: name, Inherits: fmt.Sprintf("recipe-%d-%d", level-1, i/2)}
+			}
+		}
+	}
+	return rs
+}
+
+func BenchmarkAllDescendants(b *testing.B) {
+	rs := benchmarkRecipes(30, 20)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		AllDescendants(rs)
+	}
+}
+
+func BenchmarkDescendantsPerRecipe(b *testing.B) {
+	rs := benchmarkRecipes(30, 20)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, recipe := range rs {
+			Descendants(recipe, rs, -1)
+		}
+	}
+}
+
+func TestLeaves(t *testing.T) {
+	leaves := Leaves(testRecipes())
+
+	if !reflect.DeepEqual(leaves, []string{"desktop", "tools-extra", "web"}) {
+		t.Fatalf("unexpected leaves %v", leaves)
+	}
+}
+
+func TestBuildOrder(t *testing.T) {
+	order, err := BuildOrder(testRecipes())
+	if err != nil {
+		t.Fatalf("error computing build order %v", err)
+	}
+
+	expected := []string{"base", "base-common", "desktop", "server", "tools", "tools-extra", "web"}
+	if !reflect.DeepEqual(order, expected) {
+		t.Fatalf("expected %v, got %v", expected, order)
+	}
+}
+
+func TestBuildOrderCycle(t *testing.T) {
+	rs := testRecipes()
+	rs["a"] = Recipe{Name: "a", Inherits: "b"}
+	rs["b"] = Recipe{Name: "b", Inherits: "a"}
+
+	_, err := BuildOrder(rs)
+	if err == nil || err.Error() != "cycle detected: a -> b -> a" {
+		t.Fatalf("expected a cycle error, got %v", err)
+	}
+}
+
+func TestBuildWaves(t *testing.T) {
+	waves, err := BuildWaves(testRecipes())
+	if err != nil {
+		t.Fatalf("error computing build waves %v", err)
+	}
+
+	expected := [][]string{{"base", "tools"}, {"base-common", "tools-extra"}, {"desktop", "server"}, {"web"}}
+	if !reflect.DeepEqual(waves, expected) {
+		t.Fatalf("expected %v, got %v", expected, waves)
+	}
+}
+
+func TestPartitions(t *testing.T) {
+	rs := testRecipes()
+	rs["other"] = Recipe{Name: "other", Inherits: "archlinux/base", InheritsExternal: true}
+
+	partitions, err := Partitions(rs, false)
+	if err != nil {
+		t.Fatalf("error computing partitions %v", err)
+	}
+
+	expected := [][]string{{"base", "base-common", "desktop", "server", "web"}, {"other"}, {"tools", "tools-extra"}}
+	if !reflect.DeepEqual(partitions, expected) {
+		t.Fatalf("expected %v, got %v", expected, partitions)
+	}
+
+	partitions, err = Partitions(rs, true)
+	if err != nil {
+		t.Fatalf("error computing partitions %v", err)
+	}
+
+	expected = [][]string{{"base", "base-common", "desktop", "other", "server", "web"}, {"tools", "tools-extra"}}
+	if !reflect.DeepEqual(partitions, expected) {
+		t.Fatalf("expected %v, got %v", expected, partitions)
+	}
+}
+
+func TestEstimateBuildTime(t *testing.T) {
+	rs := map[string]Recipe{
+		"base":    {Name: "base", Inherits: "archlinux/base", InheritsExternal: true, BuildSeconds: 60},
+		"server":  {Name: "server", Inherits: "base", BuildSeconds: 30},
+		"desktop": {Name: "desktop", Inherits: "base", BuildSeconds: 90},
+		"web":     {Name: "web", Inherits: "server"},
+	}
+
+	waves, err := BuildWaves(rs)
+	if err != nil {
+		t.Fatalf("error building waves %v", err)
+	}
+
+	estimate := EstimateBuildTime(waves, rs)
+	expected := BuildEstimate{CriticalPathSeconds: 150, SerialSeconds: 180, Unknown: []string{"web"}}
+	if !reflect.DeepEqual(estimate, expected) {
+		t.Fatalf("expected %v, got %v", expected, estimate)
+	}
+}
+
+func TestNearby(t *testing.T) {
+	rs := testRecipes()
+
+	if nearby := Nearby(rs["server"], rs, 0); len(nearby) != 0 {
+		t.Fatalf("expected no recipes, got %v", nearby)
+	}
+
+	expected := []string{"base-common", "web"}
+	if nearby := Nearby(rs["server"], rs, 1); !reflect.DeepEqual(nearby, expected) {
+		t.Fatalf("expected %v, got %v", expected, nearby)
+	}
+
+	expected = []string{"base", "base-common", "desktop", "web"}
+	if nearby := Nearby(rs["server"], rs, 2); !reflect.DeepEqual(nearby, expected) {
+		t.Fatalf("expected %v, got %v", expected, nearby)
+	}
+}
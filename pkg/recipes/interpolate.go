@@ -0,0 +1,50 @@
+package recipes
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// interpolate Replaces each ${VAR} in value with the variable returned by
+// lookup, or ${VAR:-default} with default when the variable isn't set or is
+// empty. A $ that isn't followed by { is left as it is. Returns an error for
+// variables that aren't set and have no default.
+func interpolate(value string, lookup func(string) (string, bool)) (string, error) {
+	var buffer bytes.Buffer
+	for {
+		start := strings.Index(value, "${")
+		if start < 0 {
+			buffer.WriteString(value)
+			return buffer.String(), nil
+		}
+		end := strings.Index(value[start:], "}")
+		if end < 0 {
+			return "", fmt.Errorf("unterminated variable in %q", value)
+		}
+		end += start
+
+		buffer.WriteString(value[:start])
+
+		name := value[start+2 : end]
+		fallback, hasFallback := "", false
+		if i := strings.Index(name, ":-"); i >= 0 {
+			name, fallback, hasFallback = name[:i], name[i+2:], true
+		}
+		if len(name) == 0 {
+			return "", fmt.Errorf("empty variable name in %q", value)
+		}
+
+		variable, ok := lookup(name)
+		switch {
+		case ok && (len(variable) > 0 || !hasFallback):
+			buffer.WriteString(variable)
+		case hasFallback:
+			buffer.WriteString(fallback)
+		default:
+			return "", fmt.Errorf("variable %s isn't set", name)
+		}
+
+		value = value[end+1:]
+	}
+}
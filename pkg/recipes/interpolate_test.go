@@ -0,0 +1,46 @@
+package recipes
+
+import (
+	"testing"
+)
+
+func TestInterpolate(t *testing.T) {
+	lookup := func(name string) (string, bool) {
+		variables := map[string]string{"REGISTRY": "registry:5000", "EMPTY": ""}
+		value, ok := variables[name]
+		return value, ok
+	}
+
+	tests := []struct {
+		value    string
+		expected string
+		err      bool
+	}{
+		{value: "archlinux/base", expected: "archlinux/base"},
+		{value: "${REGISTRY}/base", expected: "registry:5000/base"},
+		{value: "${TAG:-latest}", expected: "latest"},
+		{value: "${REGISTRY:-docker.io}/base", expected: "registry:5000/base"},
+		{value: "${EMPTY:-fallback}", expected: "fallback"},
+		{value: "${EMPTY}", expected: ""},
+		{value: "cost $5", expected: "cost $5"},
+		{value: "${UNDEFINED}", err: true},
+		{value: "${REGISTRY", err: true},
+		{value: "${}", err: true},
+	}
+
+	for _, test := range tests {
+		result, err := interpolate(test.value, lookup)
+		if test.err {
+			if err == nil {
+				t.Fatalf("expected an error for %q, got %q", test.value, result)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("error interpolating %q %v", test.value, err)
+		}
+		if result != test.expected {
+			t.Fatalf("expected %q for %q, got %q", test.expected, test.value, result)
+		}
+	}
+}
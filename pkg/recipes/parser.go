@@ -3,18 +3,21 @@ package recipes
 import (
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
+	"io"
+	"io/fs"
+	"os"
 	"path"
 	"strings"
-
-	"github.com/godarch/darch/pkg/utils"
 )
 
 type recipeConfiguration struct {
-	Inherits string `json:"inherits"`
+	Inherits  string            `json:"inherits"`
+	BuildArgs map[string]string `json:"buildArgs,omitempty"`
+	// BuildSeconds A hint of how long the recipe takes to build, 0 if unknown.
+	BuildSeconds int `json:"buildSeconds,omitempty"`
 }
 
-func parseRecipe(recipesDir string, recipeName string) (Recipe, error) {
+func parseRecipe(fsys fs.FS, recipesDir string, recipeName string, options LoadOptions) (Recipe, error) {
 	recipe := Recipe{}
 
 	if len(recipesDir) == 0 {
@@ -25,20 +28,26 @@ func parseRecipe(recipesDir string, recipeName string) (Recipe, error) {
 		return recipe, fmt.Errorf("A recipe name must be provided")
 	}
 
-	recipe.RecipesDir = utils.ExpandPath(recipesDir)
+	recipe.RecipesDir = recipesDir
 	recipe.RecipeDir = path.Join(recipe.RecipesDir, recipeName)
 	recipe.Name = recipeName
 
-	if !utils.DirectoryExists(recipe.RecipeDir) {
-		return recipe, fmt.Errorf("Image directory %s doesn't exist", recipe.RecipeDir)
+	if stat, err := statWithRetries(fsys, recipe.RecipeDir, options.ReadRetries); err != nil || !stat.IsDir() {
+		return recipe, fmt.Errorf("Image directory for recipe %s doesn't exist", recipeName)
 	}
 
-	recipeConfiguration, err := loadRecipeConfiguration(recipe)
+	recipeConfiguration, err := loadRecipeConfiguration(fsys, recipe, options)
 
 	if err != nil {
 		return recipe, err
 	}
 
+	applyRecipeConfiguration(&recipe, recipeConfiguration)
+
+	return recipe, nil
+}
+
+func applyRecipeConfiguration(recipe *Recipe, recipeConfiguration recipeConfiguration) {
 	if strings.HasPrefix(recipeConfiguration.Inherits, "external:") {
 		recipe.InheritsExternal = true
 		recipe.Inherits = recipeConfiguration.Inherits[len("external:"):len(recipeConfiguration.Inherits)]
@@ -47,18 +56,26 @@ func parseRecipe(recipesDir string, recipeName string) (Recipe, error) {
 		recipe.Inherits = recipeConfiguration.Inherits
 	}
 
-	return recipe, nil
+	recipe.BuildArgs = make(map[string]string, len(recipeConfiguration.BuildArgs))
+	for key, value := range recipeConfiguration.BuildArgs {
+		recipe.BuildArgs[key] = value
+	}
+	recipe.BuildSeconds = recipeConfiguration.BuildSeconds
 }
 
-func loadRecipeConfiguration(recipe Recipe) (recipeConfiguration, error) {
+func loadRecipeConfiguration(fsys fs.FS, recipe Recipe, options LoadOptions) (recipeConfiguration, error) {
 	recipeConfigurationPath := path.Join(recipe.RecipeDir, "config.json")
 	recipeConfiguration := recipeConfiguration{}
 
-	if !utils.FileExists(recipeConfigurationPath) {
-		return recipeConfiguration, fmt.Errorf("No configuration file exists at %s", recipeConfigurationPath)
+	stat, err := statWithRetries(fsys, recipeConfigurationPath, options.ReadRetries)
+	if os.IsPermission(err) {
+		return recipeConfiguration, err
+	}
+	if err != nil || stat.IsDir() {
+		return recipeConfiguration, fmt.Errorf("No configuration file exists for recipe %s", recipe.Name)
 	}
 
-	jsonData, err := ioutil.ReadFile(recipeConfigurationPath)
+	jsonData, err := readFileWithRetries(fsys, recipeConfigurationPath, options.ReadRetries)
 
 	if err != nil {
 		return recipeConfiguration, err
@@ -70,9 +87,53 @@ func loadRecipeConfiguration(recipe Recipe) (recipeConfiguration, error) {
 		return recipeConfiguration, err
 	}
 
+	return recipeConfiguration, checkRecipeConfiguration(recipe.Name, &recipeConfiguration, options)
+}
+
+// checkRecipeConfiguration Interpolates the configuration of a recipe, and
+// checks that it is complete.
+func checkRecipeConfiguration(recipeName string, recipeConfiguration *recipeConfiguration, options LoadOptions) error {
+	var err error
+
+	if !options.NoInterpolate {
+		if recipeConfiguration.Inherits, err = interpolate(recipeConfiguration.Inherits, os.LookupEnv); err != nil {
+			return fmt.Errorf("Invalid inherit property for image %s: %v", recipeName, err)
+		}
+		for key, value := range recipeConfiguration.BuildArgs {
+			if recipeConfiguration.BuildArgs[key], err = interpolate(value, os.LookupEnv); err != nil {
+				return fmt.Errorf("Invalid build arg %s for image %s: %v", key, recipeName, err)
+			}
+		}
+	}
+
 	if len(recipeConfiguration.Inherits) == 0 {
-		return recipeConfiguration, fmt.Errorf("No inherit property given for image %s", recipe.Name)
+		return fmt.Errorf("No inherit property given for image %s", recipeName)
+	}
+
+	if recipeConfiguration.BuildSeconds < 0 {
+		return fmt.Errorf("Invalid buildSeconds property for image %s, must not be negative", recipeName)
+	}
+
+	return nil
+}
+
+// WriteRecipe Writes the configuration of a recipe in the config.json
+// format that recipes are parsed from.
+func WriteRecipe(w io.Writer, recipe Recipe) error {
+	configuration := recipeConfiguration{
+		Inherits:     recipe.Inherits,
+		BuildArgs:    recipe.BuildArgs,
+		BuildSeconds: recipe.BuildSeconds,
+	}
+	if recipe.InheritsExternal {
+		configuration.Inherits = "external:" + recipe.Inherits
+	}
+
+	jsonData, err := json.MarshalIndent(configuration, "", "    ")
+	if err != nil {
+		return err
 	}
 
-	return recipeConfiguration, nil
+	_, err = w.Write(append(jsonData, '\n'))
+	return err
 }
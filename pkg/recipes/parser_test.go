@@ -0,0 +1,128 @@
+package recipes
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"github.com/godarch/darch/pkg/utils"
+)
+
+func TestWriteRecipeRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	recipesDir := path.Join(os.TempDir(), utils.NewID())
+	defer os.RemoveAll(recipesDir)
+
+	writeTestRecipe(t, recipesDir, "base", "external:archlinux/base")
+	writeTestRecipe(t, recipesDir, "base-common", "base")
+	err := ioutil.WriteFile(path.Join(recipesDir, "base-common", "config.json"), []byte(`{"inherits": "base", "buildArgs": {"LOCALE": "en_US"}}`), 0644)
+	if err != nil {
+		t.Fatalf("error writing recipe configuration %v", err)
+	}
+
+	for _, name := range []string{"base", "base-common"} {
+		parsed, err := parseRecipe(os.DirFS(recipesDir), ".", name, LoadOptions{})
+		if err != nil {
+			t.Fatalf("error parsing recipe %v", err)
+		}
+
+		var buffer bytes.Buffer
+		err = WriteRecipe(&buffer, parsed)
+		if err != nil {
+			t.Fatalf("error writing recipe %v", err)
+		}
+
+		err = ioutil.WriteFile(path.Join(recipesDir, name, "config.json"), buffer.Bytes(), 0644)
+		if err != nil {
+			t.Fatalf("error writing recipe configuration %v", err)
+		}
+
+		reparsed, err := parseRecipe(os.DirFS(recipesDir), ".", name, LoadOptions{})
+		if err != nil {
+			t.Fatalf("error parsing written recipe %v", err)
+		}
+
+		if !reflect.DeepEqual(reparsed, parsed) {
+			t.Fatalf("expected %v, got %v", parsed, reparsed)
+		}
+	}
+}
+
+func TestParseRecipeBuildArgs(t *testing.T) {
+	fsys := fstest.MapFS{
+		"base/config.json": &fstest.MapFile{Data: []byte(`{"inherits": "external:archlinux/base"}`)},
+		"web/config.json":  &fstest.MapFile{Data: []byte(`{"inherits": "base", "buildArgs": {"PORT": "8080"}}`)},
+	}
+
+	base, err := parseRecipe(fsys, ".", "base", LoadOptions{})
+	if err != nil {
+		t.Fatalf("error parsing recipe %v", err)
+	}
+
+	if base.BuildArgs == nil || len(base.BuildArgs) != 0 {
+		t.Fatalf("expected empty build args, got %v", base.BuildArgs)
+	}
+
+	web, err := parseRecipe(fsys, ".", "web", LoadOptions{})
+	if err != nil {
+		t.Fatalf("error parsing recipe %v", err)
+	}
+
+	if !reflect.DeepEqual(web.BuildArgs, map[string]string{"PORT": "8080"}) {
+		t.Fatalf("unexpected build args %v", web.BuildArgs)
+	}
+}
+
+func TestParseRecipeBuildSeconds(t *testing.T) {
+	fsys := fstest.MapFS{
+		"web/config.json":     &fstest.MapFile{Data: []byte(`{"inherits": "base", "buildSeconds": 120}`)},
+		"invalid/config.json": &fstest.MapFile{Data: []byte(`{"inherits": "base", "buildSeconds": -1}`)},
+	}
+
+	web, err := parseRecipe(fsys, ".", "web", LoadOptions{})
+	if err != nil {
+		t.Fatalf("error parsing recipe %v", err)
+	}
+	if web.BuildSeconds != 120 {
+		t.Fatalf("expected 120 build seconds, got %d", web.BuildSeconds)
+	}
+
+	if _, err = parseRecipe(fsys, ".", "invalid", LoadOptions{}); err == nil {
+		t.Fatal("expected an error for negative build seconds")
+	}
+}
+
+func TestParseRecipeInterpolation(t *testing.T) {
+	os.Setenv("DARCH_TEST_BASE", "archlinux/base")
+	defer os.Unsetenv("DARCH_TEST_BASE")
+
+	fsys := fstest.MapFS{
+		"base/config.json":  &fstest.MapFile{Data: []byte(`{"inherits": "external:${DARCH_TEST_BASE}", "buildArgs": {"TAG": "${DARCH_TEST_TAG:-latest}"}}`)},
+		"other/config.json": &fstest.MapFile{Data: []byte(`{"inherits": "external:${DARCH_TEST_UNDEFINED}"}`)},
+	}
+
+	base, err := parseRecipe(fsys, ".", "base", LoadOptions{})
+	if err != nil {
+		t.Fatalf("error parsing recipe %v", err)
+	}
+	if base.Inherits != "archlinux/base" || base.BuildArgs["TAG"] != "latest" {
+		t.Fatalf("unexpected recipe %v", base)
+	}
+
+	if _, err = parseRecipe(fsys, ".", "other", LoadOptions{}); err == nil {
+		t.Fatal("expected an error for an undefined variable")
+	}
+
+	other, err := parseRecipe(fsys, ".", "other", LoadOptions{NoInterpolate: true})
+	if err != nil {
+		t.Fatalf("error parsing recipe %v", err)
+	}
+	if other.Inherits != "${DARCH_TEST_UNDEFINED}" {
+		t.Fatalf("expected the variable to be left as is, got %s", other.Inherits)
+	}
+}
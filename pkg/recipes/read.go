@@ -0,0 +1,86 @@
+package recipes
+
+import (
+	"io/fs"
+	"syscall"
+	"time"
+)
+
+var (
+	// readFile is used to read recipe configurations. This declaration
+	// allows us to replace it for testing.
+	readFile = fs.ReadFile
+
+	// readRetryDelay is how long to wait before the first retry. The
+	// delay doubles after each attempt.
+	readRetryDelay = 50 * time.Millisecond
+)
+
+// withRetries Calls f, retrying up to retries times when it fails with a
+// transient error.
+func withRetries(retries int, f func() error) error {
+	delay := readRetryDelay
+	for attempt := 0; ; attempt++ {
+		err := f()
+		if err == nil || attempt >= retries || !isTransient(err) {
+			return err
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// readFileWithRetries Reads a file, retrying up to retries times when the
+// read fails with a transient error.
+func readFileWithRetries(fsys fs.FS, file string, retries int) ([]byte, error) {
+	var data []byte
+	err := withRetries(retries, func() error {
+		var err error
+		data, err = readFile(fsys, file)
+		return err
+	})
+	return data, err
+}
+
+// statWithRetries Stats a file, retrying up to retries times when the stat
+// fails with a transient error.
+func statWithRetries(fsys fs.FS, file string, retries int) (fs.FileInfo, error) {
+	var info fs.FileInfo
+	err := withRetries(retries, func() error {
+		var err error
+		info, err = fs.Stat(fsys, file)
+		return err
+	})
+	return info, err
+}
+
+// readDirWithRetries Reads a directory, retrying up to retries times when
+// the read fails with a transient error.
+func readDirWithRetries(fsys fs.FS, dir string, retries int) ([]fs.DirEntry, error) {
+	var entries []fs.DirEntry
+	err := withRetries(retries, func() error {
+		var err error
+		entries, err = fs.ReadDir(fsys, dir)
+		return err
+	})
+	return entries, err
+}
+
+// isTransient Returns true for errors that may succeed if tried again,
+// like those from a flaky network mount. Errors such as a missing file
+// or denied permission are not transient.
+func isTransient(err error) bool {
+	if pathErr, ok := err.(*fs.PathError); ok {
+		err = pathErr.Err
+	}
+	if timeout, ok := err.(interface {
+		Timeout() bool
+	}); ok && timeout.Timeout() {
+		return true
+	}
+	switch err {
+	case syscall.EAGAIN, syscall.EINTR, syscall.ETIMEDOUT, syscall.EBUSY:
+		return true
+	}
+	return false
+}
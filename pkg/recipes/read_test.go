@@ -0,0 +1,115 @@
+package recipes
+
+import (
+	"io/fs"
+	"os"
+	"sync"
+	"syscall"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/godarch/darch/pkg/utils"
+)
+
+func TestReadFileWithRetries(t *testing.T) {
+	defer func(original func(fs.FS, string) ([]byte, error), delay time.Duration) {
+		readFile = original
+		readRetryDelay = delay
+	}(readFile, readRetryDelay)
+	readRetryDelay = time.Millisecond
+
+	attempts := 0
+	readFile = func(fsys fs.FS, file string) ([]byte, error) {
+		attempts++
+		if attempts < 3 {
+			return nil, &os.PathError{Op: "read", Path: file, Err: syscall.EAGAIN}
+		}
+		return []byte("{}"), nil
+	}
+
+	data, err := readFileWithRetries(nil, "config.json", 2)
+	if err != nil {
+		t.Fatalf("expected the read to succeed after retrying, got %v", err)
+	}
+	if string(data) != "{}" || attempts != 3 {
+		t.Fatalf("unexpected result %s after %d attempts", data, attempts)
+	}
+
+	attempts = 0
+	_, err = readFileWithRetries(nil, "config.json", 1)
+	if err == nil || attempts != 2 {
+		t.Fatalf("expected the read to fail after 2 attempts, got %v after %d", err, attempts)
+	}
+}
+
+func TestReadFileWithRetriesNotTransient(t *testing.T) {
+	defer func(original func(fs.FS, string) ([]byte, error)) {
+		readFile = original
+	}(readFile)
+
+	attempts := 0
+	readFile = func(fsys fs.FS, file string) ([]byte, error) {
+		attempts++
+		return nil, &os.PathError{Op: "open", Path: file, Err: syscall.ENOENT}
+	}
+
+	_, err := readFileWithRetries(nil, "config.json", 5)
+	if err == nil || attempts != 1 {
+		t.Fatalf("expected a single failed attempt, got %v after %d", err, attempts)
+	}
+}
+
+// flakyFS Wraps an fs.FS, failing every other open of the flaky files with
+// a transient error, starting with the first. Only Open is implemented, so
+// that fs.Stat, fs.ReadDir and fs.ReadFile go through it, and each of them
+// succeeds after a single retry.
+type flakyFS struct {
+	fsys  fs.FS
+	mutex *sync.Mutex
+	opens map[string]int
+	flaky []string
+}
+
+func (f flakyFS) Open(name string) (fs.File, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.opens[name]++
+	if f.opens[name]%2 == 1 && utils.Contains(f.flaky, name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: syscall.EAGAIN}
+	}
+	return f.fsys.Open(name)
+}
+
+func TestParseAllRecipesFSRetriesStat(t *testing.T) {
+	defer func(delay time.Duration) {
+		readRetryDelay = delay
+	}(readRetryDelay)
+	readRetryDelay = time.Millisecond
+
+	newFS := func() flakyFS {
+		return flakyFS{
+			fsys: fstest.MapFS{
+				"recipes/base/config.json": &fstest.MapFile{Data: []byte(`{"inherits": "external:archlinux/base"}`)},
+			},
+			mutex: &sync.Mutex{},
+			opens: make(map[string]int),
+			// Covers the stat and read of the recipes directory, the stat
+			// of the recipe's directory, and the stat and read of its
+			// configuration.
+			flaky: []string{"recipes", "recipes/base", "recipes/base/config.json"},
+		}
+	}
+
+	rs, err := ParseAllRecipesFS(newFS(), "recipes", LoadOptions{ReadRetries: 1})
+	if err != nil {
+		t.Fatalf("expected the recipes to load after retrying, got %v", err)
+	}
+	if _, ok := rs["base"]; !ok {
+		t.Fatalf("expected base to be loaded, got %v", rs)
+	}
+
+	if _, err = ParseAllRecipesFS(newFS(), "recipes", LoadOptions{}); err == nil {
+		t.Fatal("expected an error without retries")
+	}
+}
@@ -1,8 +1,17 @@
 package recipes
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
 
+	"github.com/gobwas/glob"
 	"github.com/godarch/darch/pkg/utils"
 )
 
@@ -13,61 +22,365 @@ type Recipe struct {
 	RecipesDir       string
 	Inherits         string
 	InheritsExternal bool
+	// BuildArgs The build arguments for the recipe, never nil.
+	BuildArgs map[string]string
+	// BuildSeconds A hint of how long the recipe takes to build, 0 if unknown.
+	BuildSeconds int
 }
 
-func verifyDependencies(recipe Recipe, recipes map[string]Recipe, currentStack map[string]bool) error {
-	if currentStack == nil {
-		currentStack = make(map[string]bool, 0)
+// LoadOptions Options controlling how recipes are loaded from a recipe directory.
+type LoadOptions struct {
+	// AllowCaseCollision Load recipes whose names differ only in case,
+	// instead of failing. Use CaseCollisions to report them.
+	AllowCaseCollision bool
+	// Only Only load the recipes whose name matches this glob, along with
+	// their parents. All recipes are loaded when empty.
+	Only string
+	// Concurrency The number of recipes to parse at once. Defaults to the
+	// number of CPUs when zero, and 1 parses recipes one at a time.
+	Concurrency int
+	// ReadRetries The number of times to retry reading the recipes
+	// directory, or a recipe's directory and configuration, after a
+	// transient error, such as a timeout.
+	ReadRetries int
+	// NoInterpolate Leave ${VAR} references in recipe configurations as
+	// they are, instead of replacing them with environment variables.
+	NoInterpolate bool
+}
+
+// LoadError The recipes that couldn't be loaded, while the rest of the
+// recipes were. It is returned along with the recipes that were loaded.
+type LoadError struct {
+	Problems []Problem
+	// files The file each problem is for, relative to the filesystem the
+	// recipes were loaded from.
+	files []string
+}
+
+func (err *LoadError) Error() string {
+	messages := make([]string, 0, len(err.Problems))
+	for _, problem := range err.Problems {
+		messages = append(messages, problem.Message)
 	}
+	return strings.Join(messages, "; ")
+}
 
-	if recipe.InheritsExternal {
-		// we reached the end, all good!
-		return nil
+// Skipped Returns true if the recipe couldn't be loaded. It can be called
+// on a nil *LoadError, when all the recipes were loaded.
+func (err *LoadError) Skipped(recipeName string) bool {
+	if err == nil {
+		return false
+	}
+	for _, problem := range err.Problems {
+		if problem.Recipes[0] == recipeName {
+			return true
+		}
 	}
+	return false
+}
+
+func (err *LoadError) addUnreadable(recipeName string, file string) {
+	err.Problems = append(err.Problems, Problem{
+		Kind:    ProblemUnreadable,
+		Recipes: []string{recipeName},
+		Message: fmt.Sprintf("permission denied reading %s", file),
+	})
+	err.files = append(err.files, file)
+}
 
-	if _, ok := currentStack[recipe.Inherits]; ok {
-		// Cyclical dependency detected!
-		return fmt.Errorf("Recipe %s has a cyclical dependency", recipe.Name)
+func (err *LoadError) addInvalid(recipeName string, cause error) {
+	err.Problems = append(err.Problems, Problem{
+		Kind:    ProblemInvalid,
+		Recipes: []string{recipeName},
+		Message: fmt.Sprintf("recipe %s couldn't be parsed: %v", recipeName, cause),
+	})
+	err.files = append(err.files, "")
+}
+
+// checkRecipesDir Makes sure the recipes directory exists, is a directory
+// and can be read, returning an error that says which of those it isn't.
+func checkRecipesDir(recipesDir string) error {
+	stat, err := os.Stat(recipesDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("recipes directory %q does not exist", recipesDir)
+		}
+		if os.IsPermission(err) {
+			return fmt.Errorf("permission denied accessing recipes directory %q", recipesDir)
+		}
+		return err
 	}
 
-	// Make this image as having been traversed.
-	currentStack[recipe.Name] = true
+	if !stat.IsDir() {
+		return fmt.Errorf("recipes directory %q is not a directory", recipesDir)
+	}
 
-	if parent, ok := recipes[recipe.Inherits]; ok {
-		return verifyDependencies(parent, recipes, currentStack)
+	dir, err := os.Open(recipesDir)
+	if err != nil {
+		if os.IsPermission(err) {
+			return fmt.Errorf("permission denied accessing recipes directory %q", recipesDir)
+		}
+		return err
 	}
+	dir.Close()
 
-	return fmt.Errorf("Recipe defintion %s inherits from %s, which doesn't exist", recipe.Name, recipe.Inherits)
+	return nil
 }
 
-// GetAllRecipes Return all the recipes in a recipe directory
-func GetAllRecipes(recipesDir string) (map[string]Recipe, error) {
+// checkRecipesDirFS Same as checkRecipesDir, for a directory in fsys.
+func checkRecipesDirFS(fsys fs.FS, recipesDir string, retries int) error {
+	stat, err := statWithRetries(fsys, recipesDir, retries)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("recipes directory %q does not exist", recipesDir)
+		}
+		if os.IsPermission(err) {
+			return fmt.Errorf("permission denied accessing recipes directory %q", recipesDir)
+		}
+		return err
+	}
+
+	if !stat.IsDir() {
+		return fmt.Errorf("recipes directory %q is not a directory", recipesDir)
+	}
+
+	return nil
+}
+
+// getChildDirectories Gets the sorted child directories in the given directory, excluding hidden ones.
+// Reading the directory is retried up to retries times after a transient error.
+func getChildDirectories(fsys fs.FS, dir string, retries int) ([]string, error) {
+	entries, err := readDirWithRetries(fsys, dir, retries)
+	if err != nil {
+		if os.IsPermission(err) {
+			return nil, fmt.Errorf("permission denied accessing recipes directory %q", dir)
+		}
+		return nil, err
+	}
+	directories := make([]string, 0)
+	for _, entry := range entries {
+		if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
+			directories = append(directories, entry.Name())
+		}
+	}
+	sort.Strings(directories)
+	return directories, nil
+}
+
+// CaseCollisions Returns each group of names that differ only in case.
+// These would collide on a case-insensitive filesystem.
+func CaseCollisions(names []string) [][]string {
+	groups := make(map[string][]string, 0)
+	keys := make([]string, 0)
+	for _, name := range names {
+		key := strings.ToLower(name)
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], name)
+	}
+	sort.Strings(keys)
+
+	result := make([][]string, 0)
+	for _, key := range keys {
+		if len(groups[key]) > 1 {
+			sort.Strings(groups[key])
+			result = append(result, groups[key])
+		}
+	}
+	return result
+}
+
+// ParseAllRecipes Parses all the recipes in a recipe directory without
+// verifying that their dependencies are satisfied. Use Validate to find
+// any problems with the result.
+func ParseAllRecipes(recipesDir string) (map[string]Recipe, error) {
+	return ParseAllRecipesWithOptions(recipesDir, LoadOptions{})
+}
+
+// ParseAllRecipesWithOptions Same as ParseAllRecipes, with the given options.
+func ParseAllRecipesWithOptions(recipesDir string, options LoadOptions) (map[string]Recipe, error) {
 	if len(recipesDir) == 0 {
 		return nil, fmt.Errorf("An image directory must be provided")
 	}
 
-	recipeNames, err := utils.GetChildDirectories(recipesDir)
+	recipesDir = utils.ExpandPath(recipesDir)
+
+	if err := checkRecipesDir(recipesDir); err != nil {
+		return nil, err
+	}
+
+	recipes, err := ParseAllRecipesFS(os.DirFS(recipesDir), ".", options)
+	if loadErr, ok := err.(*LoadError); ok {
+		// Report the files relative to the OS, rather than to fsys.
+		result := &LoadError{}
+		for i, problem := range loadErr.Problems {
+			if problem.Kind == ProblemUnreadable {
+				result.addUnreadable(problem.Recipes[0], path.Join(recipesDir, loadErr.files[i]))
+			} else {
+				result.Problems = append(result.Problems, problem)
+				result.files = append(result.files, loadErr.files[i])
+			}
+		}
+		err = result
+	} else if err != nil {
+		return nil, err
+	}
+
+	// Builds mount the recipes from the OS, so use its paths rather than those in fsys.
+	for name, recipe := range recipes {
+		recipe.RecipesDir = recipesDir
+		recipe.RecipeDir = path.Join(recipesDir, name)
+		recipes[name] = recipe
+	}
+
+	return recipes, err
+}
+
+// ParseAllRecipesFS Same as ParseAllRecipesWithOptions, reading the recipes
+// from recipesDir in fsys. This allows recipes to be loaded from an embed.FS,
+// or from an fstest.MapFS in tests.
+//
+// Recipes that can't be read due to permissions, or can't be parsed, are
+// skipped, and the rest are still loaded. In that case, the loaded recipes
+// are returned along with a *LoadError listing the skipped ones.
+func ParseAllRecipesFS(fsys fs.FS, recipesDir string, options LoadOptions) (map[string]Recipe, error) {
+	if len(recipesDir) == 0 {
+		return nil, fmt.Errorf("An image directory must be provided")
+	}
+
+	if err := checkRecipesDirFS(fsys, recipesDir, options.ReadRetries); err != nil {
+		return nil, err
+	}
+
+	recipeNames, err := getChildDirectories(fsys, recipesDir, options.ReadRetries)
 
 	if err != nil {
 		return nil, err
 	}
 
-	recipes := make(map[string]Recipe, 0)
+	if !options.AllowCaseCollision {
+		if collisions := CaseCollisions(recipeNames); len(collisions) > 0 {
+			return nil, fmt.Errorf("recipes %s differ only in case, which is ambiguous on case-insensitive filesystems", strings.Join(collisions[0], ", "))
+		}
+	}
 
-	for _, recipeName := range recipeNames {
-		recipe, err := parseRecipe(recipesDir, recipeName)
+	pending := recipeNames
+	if len(options.Only) > 0 {
+		g, err := glob.Compile(options.Only)
 		if err != nil {
 			return nil, err
 		}
-		recipes[recipeName] = recipe
+		pending = make([]string, 0)
+		for _, recipeName := range recipeNames {
+			if g.Match(recipeName) {
+				pending = append(pending, recipeName)
+			}
+		}
 	}
 
-	// verify dependencies are satisfied and no circular dependencies
-	for _, recipe := range recipes {
-		err := verifyDependencies(recipe, recipes, nil)
+	recipes := make(map[string]Recipe, 0)
+	loadErr := &LoadError{}
+
+	for len(pending) > 0 {
+		parsed, err := parseRecipes(fsys, recipesDir, pending, options, loadErr)
 		if err != nil {
 			return nil, err
 		}
+
+		pending = make([]string, 0)
+		for _, recipe := range parsed {
+			recipes[recipe.Name] = recipe
+		}
+		for _, recipe := range parsed {
+			// Parents are always loaded, even when they don't match, so
+			// the chain of every loaded recipe can still be walked.
+			if recipe.InheritsExternal || !utils.Contains(recipeNames, recipe.Inherits) {
+				continue
+			}
+			if _, ok := recipes[recipe.Inherits]; !ok && !utils.Contains(pending, recipe.Inherits) && !loadErr.Skipped(recipe.Inherits) {
+				pending = append(pending, recipe.Inherits)
+			}
+		}
+	}
+
+	if len(loadErr.Problems) > 0 {
+		return recipes, loadErr
+	}
+
+	return recipes, nil
+}
+
+// parseRecipes Parses the given recipes using a pool of workers. The
+// result is in the same order as recipeNames. Recipes that can't be read
+// due to permissions, or can't be parsed, are added to loadErr and left
+// out of the result.
+func parseRecipes(fsys fs.FS, recipesDir string, recipeNames []string, options LoadOptions, loadErr *LoadError) ([]Recipe, error) {
+	concurrency := options.Concurrency
+	if concurrency <= 0 {
+		concurrency = runtime.NumCPU()
+	}
+
+	var (
+		wg      sync.WaitGroup
+		indexes = make(chan int)
+		result  = make([]Recipe, len(recipeNames))
+		errs    = make([]error, len(recipeNames))
+	)
+
+	for worker := 0; worker < concurrency; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				result[i], errs[i] = parseRecipe(fsys, recipesDir, recipeNames[i], options)
+			}
+		}()
+	}
+
+	for i := range recipeNames {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	parsed := make([]Recipe, 0, len(result))
+	for i, err := range errs {
+		if pathErr, ok := err.(*fs.PathError); ok && os.IsPermission(err) {
+			loadErr.addUnreadable(recipeNames[i], pathErr.Path)
+		} else if err != nil {
+			loadErr.addInvalid(recipeNames[i], err)
+		} else {
+			parsed = append(parsed, result[i])
+		}
+	}
+
+	return parsed, nil
+}
+
+// GetAllRecipes Return all the recipes in a recipe directory
+func GetAllRecipes(recipesDir string) (map[string]Recipe, error) {
+	return GetAllRecipesWithOptions(recipesDir, LoadOptions{})
+}
+
+// GetAllRecipesWithOptions Same as GetAllRecipes, with the given options.
+func GetAllRecipesWithOptions(recipesDir string, options LoadOptions) (map[string]Recipe, error) {
+	return verifyRecipes(ParseAllRecipesWithOptions(recipesDir, options))
+}
+
+// GetAllRecipesFS Same as GetAllRecipesWithOptions, reading the recipes from recipesDir in fsys.
+func GetAllRecipesFS(fsys fs.FS, recipesDir string, options LoadOptions) (map[string]Recipe, error) {
+	return verifyRecipes(ParseAllRecipesFS(fsys, recipesDir, options))
+}
+
+func verifyRecipes(recipes map[string]Recipe, err error) (map[string]Recipe, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	// verify dependencies are satisfied and no circular dependencies
+	if problems := Validate(recipes); len(problems) > 0 {
+		return nil, errors.New(problems[0].Message)
 	}
 
 	return recipes, nil
@@ -0,0 +1,353 @@
+package recipes
+
+import (
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/godarch/darch/pkg/utils"
+)
+
+func TestGetAllRecipesMissingDir(t *testing.T) {
+	t.Parallel()
+
+	recipesDir := path.Join(os.TempDir(), utils.NewID())
+
+	_, err := GetAllRecipes(recipesDir)
+	if err == nil {
+		t.Fatal("expected an error for a missing recipes directory")
+	}
+
+	if !strings.Contains(err.Error(), "does not exist") {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestGetAllRecipesNotADir(t *testing.T) {
+	t.Parallel()
+
+	recipesDir := path.Join(os.TempDir(), utils.NewID())
+	defer os.RemoveAll(recipesDir)
+
+	err := ioutil.WriteFile(recipesDir, []byte{}, 0644)
+	if err != nil {
+		t.Fatalf("error creating file %v", err)
+	}
+
+	_, err = GetAllRecipes(recipesDir)
+	if err == nil {
+		t.Fatal("expected an error for a recipes directory that is a file")
+	}
+
+	if !strings.Contains(err.Error(), "is not a directory") {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestGetAllRecipesPermissionDenied(t *testing.T) {
+	t.Parallel()
+
+	if os.Geteuid() == 0 {
+		t.Skip("permissions aren't enforced for root")
+	}
+
+	recipesDir := path.Join(os.TempDir(), utils.NewID())
+	defer os.RemoveAll(recipesDir)
+
+	err := os.Mkdir(recipesDir, 0300)
+	if err != nil {
+		t.Fatalf("error creating directory %v", err)
+	}
+
+	_, err = GetAllRecipes(recipesDir)
+	if err == nil {
+		t.Fatal("expected an error for an unreadable recipes directory")
+	}
+
+	if !strings.Contains(err.Error(), "permission denied") {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestParseAllRecipesUnreadable(t *testing.T) {
+	t.Parallel()
+
+	if os.Geteuid() == 0 {
+		t.Skip("permissions aren't enforced for root")
+	}
+
+	recipesDir := path.Join(os.TempDir(), utils.NewID())
+	defer os.RemoveAll(recipesDir)
+
+	writeTestRecipe(t, recipesDir, "base", "external:archlinux/base")
+	writeTestRecipe(t, recipesDir, "secret", "base")
+	configPath := path.Join(recipesDir, "secret", "config.json")
+	if err := os.Chmod(configPath, 0200); err != nil {
+		t.Fatalf("error changing permissions %v", err)
+	}
+
+	rs, err := ParseAllRecipes(recipesDir)
+	loadErr, ok := err.(*LoadError)
+	if !ok {
+		t.Fatalf("expected a load error, got %v", err)
+	}
+
+	if len(loadErr.Problems) != 1 || loadErr.Problems[0].Kind != ProblemUnreadable || loadErr.Problems[0].Recipes[0] != "secret" {
+		t.Fatalf("unexpected problems %v", loadErr.Problems)
+	}
+
+	if err.Error() != "permission denied reading "+configPath {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if _, ok := rs["base"]; !ok || len(rs) != 1 {
+		t.Fatalf("expected only base to be loaded, got %v", rs)
+	}
+}
+
+// deniedFS Wraps an fs.FS, and denies opening a single file, as if it couldn't
+// be read due to permissions. Only Open is implemented, so that fs.Stat and
+// fs.ReadFile go through it.
+type deniedFS struct {
+	fsys   fs.FS
+	denied string
+}
+
+func (d deniedFS) Open(name string) (fs.File, error) {
+	if name == d.denied {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrPermission}
+	}
+	return d.fsys.Open(name)
+}
+
+func TestParseAllRecipesFSSkipsBroken(t *testing.T) {
+	t.Parallel()
+
+	fsys := deniedFS{
+		fsys: fstest.MapFS{
+			"recipes/base/config.json":    &fstest.MapFile{Data: []byte(`{"inherits": "external:archlinux/base"}`)},
+			"recipes/desktop/config.json": &fstest.MapFile{Data: []byte(`{"inherits": "base"}`)},
+			"recipes/broken/config.json":  &fstest.MapFile{Data: []byte(`{"inherits": `)},
+			"recipes/secret/config.json":  &fstest.MapFile{Data: []byte(`{"inherits": "base"}`)},
+		},
+		denied: "recipes/secret/config.json",
+	}
+
+	rs, err := ParseAllRecipesFS(fsys, "recipes", LoadOptions{})
+	loadErr, ok := err.(*LoadError)
+	if !ok {
+		t.Fatalf("expected a load error, got %v", err)
+	}
+
+	kinds := make(map[string]ProblemKind)
+	for _, problem := range loadErr.Problems {
+		kinds[problem.Recipes[0]] = problem.Kind
+	}
+	expectedKinds := map[string]ProblemKind{
+		"broken": ProblemInvalid,
+		"secret": ProblemUnreadable,
+	}
+	if !reflect.DeepEqual(kinds, expectedKinds) {
+		t.Fatalf("expected problems %v, got %v", expectedKinds, loadErr.Problems)
+	}
+
+	names := make([]string, 0)
+	for name := range rs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if !reflect.DeepEqual(names, []string{"base", "desktop"}) {
+		t.Fatalf("expected base and desktop to be loaded, got %v", names)
+	}
+}
+
+func TestCaseCollisions(t *testing.T) {
+	t.Parallel()
+
+	collisions := CaseCollisions([]string{"web", "base", "Web", "WEB", "desktop"})
+
+	if !reflect.DeepEqual(collisions, [][]string{{"WEB", "Web", "web"}}) {
+		t.Fatalf("unexpected collisions %v", collisions)
+	}
+}
+
+func TestParseAllRecipesCaseCollision(t *testing.T) {
+	t.Parallel()
+
+	recipesDir := path.Join(os.TempDir(), utils.NewID())
+	defer os.RemoveAll(recipesDir)
+
+	writeTestRecipe(t, recipesDir, "web", "external:archlinux/base")
+	if utils.DirectoryExists(path.Join(recipesDir, "Web")) {
+		t.Skip("the filesystem is case-insensitive")
+	}
+	writeTestRecipe(t, recipesDir, "Web", "external:archlinux/base")
+
+	_, err := ParseAllRecipes(recipesDir)
+	if err == nil || !strings.Contains(err.Error(), "differ only in case") {
+		t.Fatalf("expected a case collision error, got %v", err)
+	}
+
+	rs, err := ParseAllRecipesWithOptions(recipesDir, LoadOptions{AllowCaseCollision: true})
+	if err != nil {
+		t.Fatalf("error loading recipes %v", err)
+	}
+
+	if len(rs) != 2 {
+		t.Fatalf("expected 2 recipes, got %d", len(rs))
+	}
+}
+
+func TestParseAllRecipesFSCaseCollision(t *testing.T) {
+	t.Parallel()
+
+	// An in-memory filesystem is case-sensitive, so this also runs where the
+	// OS filesystem isn't.
+	fsys := fstest.MapFS{
+		"recipes/web/config.json": &fstest.MapFile{Data: []byte(`{"inherits": "external:archlinux/base"}`)},
+		"recipes/Web/config.json": &fstest.MapFile{Data: []byte(`{"inherits": "external:archlinux/base"}`)},
+	}
+
+	_, err := ParseAllRecipesFS(fsys, "recipes", LoadOptions{})
+	if err == nil || !strings.Contains(err.Error(), "recipes Web, web differ only in case") {
+		t.Fatalf("expected a case collision error, got %v", err)
+	}
+
+	rs, err := ParseAllRecipesFS(fsys, "recipes", LoadOptions{AllowCaseCollision: true})
+	if err != nil {
+		t.Fatalf("error loading recipes %v", err)
+	}
+
+	if len(rs) != 2 || rs["web"].Name != "web" || rs["Web"].Name != "Web" {
+		t.Fatalf("expected web and Web to be loaded, got %v", rs)
+	}
+}
+
+func writeTestRecipe(t *testing.T, recipesDir string, name string, inherits string) {
+	recipeDir := path.Join(recipesDir, name)
+
+	err := os.MkdirAll(recipeDir, 0755)
+	if err != nil {
+		t.Fatalf("error creating recipe directory %v", err)
+	}
+
+	err = ioutil.WriteFile(path.Join(recipeDir, "config.json"), []byte(`{"inherits": "`+inherits+`"}`), 0644)
+	if err != nil {
+		t.Fatalf("error writing recipe configuration %v", err)
+	}
+}
+
+func TestParseAllRecipesOnly(t *testing.T) {
+	t.Parallel()
+
+	recipesDir := path.Join(os.TempDir(), utils.NewID())
+	defer os.RemoveAll(recipesDir)
+
+	writeTestRecipe(t, recipesDir, "base", "external:archlinux/base")
+	writeTestRecipe(t, recipesDir, "base-common", "base")
+	writeTestRecipe(t, recipesDir, "team-web", "base-common")
+	writeTestRecipe(t, recipesDir, "team-db", "base")
+	writeTestRecipe(t, recipesDir, "desktop", "base-common")
+
+	rs, err := GetAllRecipesWithOptions(recipesDir, LoadOptions{Only: "team-w*"})
+	if err != nil {
+		t.Fatalf("error loading recipes %v", err)
+	}
+
+	names := make([]string, 0)
+	for name := range rs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	expected := []string{"base", "base-common", "team-web"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Fatalf("expected %v, got %v", expected, names)
+	}
+}
+
+func TestGetAllRecipesFS(t *testing.T) {
+	t.Parallel()
+
+	config := func(inherits string) *fstest.MapFile {
+		return &fstest.MapFile{Data: []byte(`{"inherits": "` + inherits + `"}`)}
+	}
+
+	tests := []struct {
+		name     string
+		fsys     fstest.MapFS
+		expected []string
+		err      string
+	}{
+		{
+			name: "chain",
+			fsys: fstest.MapFS{
+				"recipes/base/config.json":    config("external:archlinux/base"),
+				"recipes/desktop/config.json": config("base"),
+				"recipes/.git/config":         &fstest.MapFile{},
+			},
+			expected: []string{"base", "desktop"},
+		},
+		{
+			name: "missing parent",
+			fsys: fstest.MapFS{
+				"recipes/desktop/config.json": config("base"),
+			},
+			err: "base",
+		},
+		{
+			name: "missing configuration",
+			fsys: fstest.MapFS{
+				"recipes/base/script": &fstest.MapFile{},
+			},
+			err: "No configuration file exists for recipe base",
+		},
+		{
+			name: "missing directory",
+			fsys: fstest.MapFS{},
+			err:  "does not exist",
+		},
+		{
+			name: "not a directory",
+			fsys: fstest.MapFS{
+				"recipes": &fstest.MapFile{},
+			},
+			err: "is not a directory",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			rs, err := GetAllRecipesFS(test.fsys, "recipes", LoadOptions{})
+			if len(test.err) > 0 {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("expected an error containing %q, got %v", test.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error loading recipes %v", err)
+			}
+
+			names := make([]string, 0)
+			for name, recipe := range rs {
+				if recipe.RecipeDir != path.Join("recipes", name) {
+					t.Fatalf("unexpected recipe directory %s", recipe.RecipeDir)
+				}
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			if !reflect.DeepEqual(names, test.expected) {
+				t.Fatalf("expected %v, got %v", test.expected, names)
+			}
+		})
+	}
+}
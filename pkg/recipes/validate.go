@@ -0,0 +1,371 @@
+package recipes
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ProblemKind The kind of problem found when validating recipes.
+type ProblemKind string
+
+const (
+	// ProblemEmptyName A recipe has no name.
+	ProblemEmptyName ProblemKind = "empty-name"
+	// ProblemDuplicate More than one recipe has the same name.
+	ProblemDuplicate ProblemKind = "duplicate"
+	// ProblemSelfInheritance A recipe inherits from itself.
+	ProblemSelfInheritance ProblemKind = "self-inheritance"
+	// ProblemMissingParent A recipe inherits from a recipe that doesn't exist.
+	ProblemMissingParent ProblemKind = "missing-parent"
+	// ProblemCycle A group of recipes inherit from each other.
+	ProblemCycle ProblemKind = "cycle"
+	// ProblemMissingExpected A recipe that is expected to exist doesn't.
+	ProblemMissingExpected ProblemKind = "missing-expected"
+	// ProblemUnexpected A recipe exists that isn't in the expected list.
+	ProblemUnexpected ProblemKind = "unexpected"
+	// ProblemMultipleBases The recipes are built on more than one external image.
+	ProblemMultipleBases ProblemKind = "multiple-bases"
+	// ProblemUnreadable A recipe couldn't be read due to permissions.
+	ProblemUnreadable ProblemKind = "unreadable"
+	// ProblemInvalid A recipe's configuration couldn't be parsed.
+	ProblemInvalid ProblemKind = "invalid"
+	// ProblemNamePattern A recipe's name doesn't match the required pattern.
+	ProblemNamePattern ProblemKind = "name-pattern"
+	// ProblemSlowResolve Resolving a recipe's chain took longer than allowed.
+	ProblemSlowResolve ProblemKind = "slow-resolve"
+	// ProblemConfusableNames Recipe names differ only in case or separators.
+	ProblemConfusableNames ProblemKind = "confusable-names"
+)
+
+var problemExplanations = map[ProblemKind]string{
+	ProblemEmptyName: "Recipes are identified by their name, so a recipe without one can't be built or inherited. " +
+		"Give the recipe a name.",
+	ProblemDuplicate: "Recipe names must be unique, otherwise it is ambiguous which recipe is built or inherited. " +
+		"Rename or remove one of the recipes.",
+	ProblemSelfInheritance: "A recipe can't be built on top of itself, as it would need to already exist first. " +
+		"Change the inherits property to the recipe or external image it should be built on.",
+	ProblemMissingParent: "A recipe can only be built once the recipe it inherits is built, and that recipe doesn't exist. " +
+		"Create the missing recipe, fix a typo in the inherits property, or prefix it with external: to use an image from a registry.",
+	ProblemCycle: "Recipes that inherit from each other can never be built, as each one needs another to be built first. " +
+		"Change the inherits property of one of the recipes to break the cycle.",
+	ProblemMissingExpected: "The recipe is in the expected list, but there is no directory for it. " +
+		"Create the recipe, or remove it from the expected list.",
+	ProblemUnexpected: "The recipe exists, but isn't in the expected list, so it may have been added by mistake. " +
+		"Add it to the expected list, or remove the recipe.",
+	ProblemMultipleBases: "All recipes are required to be built on a single external image, to keep them consistent. " +
+		"Change the recipes inheriting the other external images to inherit a recipe built on the approved one.",
+	ProblemNamePattern: "Recipe names are required to follow a naming convention. " +
+		"Rename the recipe's directory to match the pattern, and update the recipes inheriting it.",
+	ProblemUnreadable: "The recipe's configuration couldn't be read, so it was skipped. " +
+		"Check the permissions of the recipe's directory and config.json.",
+	ProblemInvalid: "The recipe's configuration couldn't be parsed, so it was skipped. " +
+		"Fix the error in the recipe's config.json, such as malformed JSON or a missing inherits property.",
+	ProblemSlowResolve: "Resolving the recipe's chain of parents took longer than allowed, which makes tooling sluggish. " +
+		"Look for a very deep chain, or a near-cycle, and flatten it.",
+	ProblemConfusableNames: "The recipe names only differ in case or separators, so they are easily mistaken for each other. " +
+		"Rename or merge the recipes so each name is distinct.",
+}
+
+// Explanation Returns a short explanation of why the kind of problem is a
+// problem, and how to fix it.
+func (kind ProblemKind) Explanation() string {
+	return problemExplanations[kind]
+}
+
+// Problem A problem found when validating recipes.
+type Problem struct {
+	Kind ProblemKind
+	// Recipes The names of the recipes involved. For cycles, this is the
+	// cycle itself, starting and ending with the same recipe.
+	Recipes []string
+	Message string
+}
+
+// Validate Returns all the problems found in the given recipes, ordered by recipe name.
+func Validate(recipes map[string]Recipe) []Problem {
+	problems := make([]Problem, 0)
+
+	keys := make([]string, 0, len(recipes))
+	for key := range recipes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	names := make(map[string][]string, 0)
+	for _, key := range keys {
+		recipe := recipes[key]
+		if len(recipe.Name) == 0 {
+			problems = append(problems, Problem{
+				Kind:    ProblemEmptyName,
+				Recipes: []string{key},
+				Message: fmt.Sprintf("recipe %q has no name", key),
+			})
+			continue
+		}
+		names[recipe.Name] = append(names[recipe.Name], key)
+	}
+
+	for _, key := range keys {
+		recipe := recipes[key]
+		if len(recipe.Name) == 0 {
+			continue
+		}
+
+		if duplicates := names[recipe.Name]; len(duplicates) > 1 {
+			if duplicates[0] == key {
+				problems = append(problems, Problem{
+					Kind:    ProblemDuplicate,
+					Recipes: []string{recipe.Name},
+					Message: fmt.Sprintf("recipe %s is defined %d times", recipe.Name, len(duplicates)),
+				})
+			}
+			continue
+		}
+
+		if recipe.InheritsExternal {
+			continue
+		}
+
+		if recipe.Inherits == recipe.Name {
+			problems = append(problems, Problem{
+				Kind:    ProblemSelfInheritance,
+				Recipes: []string{recipe.Name},
+				Message: fmt.Sprintf("recipe %s inherits from itself", recipe.Name),
+			})
+			continue
+		}
+
+		if _, ok := recipes[recipe.Inherits]; !ok {
+			problems = append(problems, Problem{
+				Kind:    ProblemMissingParent,
+				Recipes: []string{recipe.Name, recipe.Inherits},
+				Message: fmt.Sprintf("recipe %s inherits from %s, which doesn't exist", recipe.Name, recipe.Inherits),
+			})
+		}
+	}
+
+	problems = append(problems, findCycles(recipes, keys)...)
+
+	sort.SliceStable(problems, func(i, j int) bool {
+		return problems[i].Recipes[0] < problems[j].Recipes[0]
+	})
+
+	return problems
+}
+
+// findCycles Returns a problem for each distinct cycle. Self inheritance
+// is reported separately by Validate, so it's ignored here.
+func findCycles(recipes map[string]Recipe, keys []string) []Problem {
+	problems := make([]Problem, 0)
+	reported := make(map[string]bool, 0)
+
+	for _, key := range keys {
+		path := make([]string, 0)
+		index := make(map[string]int, 0)
+
+		current, ok := recipes[key]
+		for ok && !current.InheritsExternal && current.Inherits != current.Name {
+			if reported[current.Name] {
+				break
+			}
+			if start, seen := index[current.Name]; seen {
+				cycle := append(append([]string{}, path[start:]...), current.Name)
+				for _, name := range cycle {
+					reported[name] = true
+				}
+				problems = append(problems, Problem{
+					Kind:    ProblemCycle,
+					Recipes: cycle,
+					Message: fmt.Sprintf("cycle detected: %s", strings.Join(cycle, " -> ")),
+				})
+				break
+			}
+			index[current.Name] = len(path)
+			path = append(path, current.Name)
+			current, ok = recipes[current.Inherits]
+		}
+	}
+
+	return problems
+}
+
+// ValidateExpected Returns a problem for each expected recipe that doesn't
+// exist. When exact is true, recipes that aren't expected are also problems.
+func ValidateExpected(recipes map[string]Recipe, expected []string, exact bool) []Problem {
+	problems := make([]Problem, 0)
+
+	expectedNames := make(map[string]bool, 0)
+	for _, name := range expected {
+		expectedNames[name] = true
+	}
+
+	names := make([]string, 0)
+	for name := range expectedNames {
+		names = append(names, name)
+	}
+	if exact {
+		for name := range recipes {
+			if !expectedNames[name] {
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		_, exists := recipes[name]
+		if expectedNames[name] && !exists {
+			problems = append(problems, Problem{
+				Kind:    ProblemMissingExpected,
+				Recipes: []string{name},
+				Message: fmt.Sprintf("recipe %s is expected, but doesn't exist", name),
+			})
+		} else if !expectedNames[name] {
+			problems = append(problems, Problem{
+				Kind:    ProblemUnexpected,
+				Recipes: []string{name},
+				Message: fmt.Sprintf("recipe %s exists, but isn't expected", name),
+			})
+		}
+	}
+
+	return problems
+}
+
+// ValidateSingleBase Returns a problem if the given recipes are built on
+// more than one external image. The problem's recipes are the ones
+// inheriting directly from an external image.
+func ValidateSingleBase(recipes map[string]Recipe) []Problem {
+	bases := make([]string, 0)
+	roots := make([]string, 0)
+	for base, dependents := range ExternalBases(recipes) {
+		bases = append(bases, base)
+		roots = append(roots, dependents...)
+	}
+	sort.Strings(bases)
+	sort.Strings(roots)
+
+	if len(bases) <= 1 {
+		return make([]Problem, 0)
+	}
+
+	return []Problem{{
+		Kind:    ProblemMultipleBases,
+		Recipes: roots,
+		Message: fmt.Sprintf("expected a single external image, found %d: %s", len(bases), strings.Join(bases, ", ")),
+	}}
+}
+
+// ValidateNamePattern Returns a problem for every recipe whose full name
+// doesn't match the given regular expression. Returns an error if the
+// pattern isn't valid.
+func ValidateNamePattern(recipes map[string]Recipe, pattern string) ([]Problem, error) {
+	expression, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid name pattern %s: %v", pattern, err)
+	}
+
+	names := make([]string, 0)
+	for name := range recipes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	problems := make([]Problem, 0)
+	for _, name := range names {
+		if !expression.MatchString(name) {
+			problems = append(problems, Problem{
+				Kind:    ProblemNamePattern,
+				Recipes: []string{name},
+				Message: fmt.Sprintf("recipe %s doesn't match the name pattern %s", name, pattern),
+			})
+		}
+	}
+
+	return problems, nil
+}
+
+var nameSeparators = regexp.MustCompile(`[-_.\s]`)
+
+// ValidateConfusableNames Returns a problem for every group of recipes
+// whose names are the same after lowercasing and removing separators, such
+// as WebServer, web-server and web_server. The problems are ordered by
+// their first recipe.
+func ValidateConfusableNames(recipes map[string]Recipe) []Problem {
+	groups := make(map[string][]string, 0)
+	for name := range recipes {
+		key := nameSeparators.ReplaceAllString(strings.ToLower(name), "")
+		groups[key] = append(groups[key], name)
+	}
+
+	problems := make([]Problem, 0)
+	for _, names := range groups {
+		if len(names) < 2 {
+			continue
+		}
+		sort.Strings(names)
+		problems = append(problems, Problem{
+			Kind:    ProblemConfusableNames,
+			Recipes: names,
+			Message: fmt.Sprintf("recipes %s can be confused with each other", strings.Join(names, ", ")),
+		})
+	}
+	sort.Slice(problems, func(i, j int) bool {
+		return problems[i].Recipes[0] < problems[j].Recipes[0]
+	})
+
+	return problems
+}
+
+// ResolveTime How long resolving a recipe's chain took.
+type ResolveTime struct {
+	Name     string
+	Duration time.Duration
+}
+
+// TimeResolveChains Resolves the chain of every recipe, returning how long
+// each took, slowest first. Recipes whose chain can't be resolved are still
+// timed, Validate reports why they failed.
+func TimeResolveChains(recipes map[string]Recipe) []ResolveTime {
+	result := make([]ResolveTime, 0, len(recipes))
+	for _, recipe := range recipes {
+		start := time.Now()
+		ResolveChain(recipe, recipes)
+		result = append(result, ResolveTime{Name: recipe.Name, Duration: time.Since(start)})
+	}
+	sortResolveTimes(result)
+	return result
+}
+
+// sortResolveTimes Sorts the times slowest first, then by name.
+func sortResolveTimes(times []ResolveTime) {
+	sort.Slice(times, func(i, j int) bool {
+		if times[i].Duration != times[j].Duration {
+			return times[i].Duration > times[j].Duration
+		}
+		return times[i].Name < times[j].Name
+	})
+}
+
+// ValidateResolveTime Returns a problem for every recipe that took longer
+// than max to resolve, slowest first.
+func ValidateResolveTime(times []ResolveTime, max time.Duration) []Problem {
+	sorted := append([]ResolveTime(nil), times...)
+	sortResolveTimes(sorted)
+
+	problems := make([]Problem, 0)
+	for _, resolveTime := range sorted {
+		if resolveTime.Duration > max {
+			problems = append(problems, Problem{
+				Kind:    ProblemSlowResolve,
+				Recipes: []string{resolveTime.Name},
+				Message: fmt.Sprintf("resolving recipe %s took %s, more than %s", resolveTime.Name, resolveTime.Duration, max),
+			})
+		}
+	}
+
+	return problems
+}
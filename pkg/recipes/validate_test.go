@@ -0,0 +1,216 @@
+package recipes
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestValidateNoProblems(t *testing.T) {
+	if problems := Validate(testRecipes()); len(problems) != 0 {
+		t.Fatalf("expected no problems, got %v", problems)
+	}
+}
+
+func TestValidateMissingParent(t *testing.T) {
+	rs := testRecipes()
+	rs["orphan"] = Recipe{Name: "orphan", Inherits: "missing"}
+
+	problems := Validate(rs)
+	if len(problems) != 1 {
+		t.Fatalf("expected one problem, got %v", problems)
+	}
+
+	if problems[0].Kind != ProblemMissingParent {
+		t.Fatalf("expected %s, got %s", ProblemMissingParent, problems[0].Kind)
+	}
+
+	if !reflect.DeepEqual(problems[0].Recipes, []string{"orphan", "missing"}) {
+		t.Fatalf("unexpected recipes %v", problems[0].Recipes)
+	}
+}
+
+func TestValidateSelfInheritance(t *testing.T) {
+	rs := testRecipes()
+	rs["self"] = Recipe{Name: "self", Inherits: "self"}
+
+	problems := Validate(rs)
+	if len(problems) != 1 || problems[0].Kind != ProblemSelfInheritance {
+		t.Fatalf("expected a self inheritance problem, got %v", problems)
+	}
+}
+
+func TestValidateCycle(t *testing.T) {
+	rs := testRecipes()
+	rs["a"] = Recipe{Name: "a", Inherits: "b"}
+	rs["b"] = Recipe{Name: "b", Inherits: "c"}
+	rs["c"] = Recipe{Name: "c", Inherits: "a"}
+	rs["d"] = Recipe{Name: "d", Inherits: "a"}
+
+	problems := Validate(rs)
+	if len(problems) != 1 || problems[0].Kind != ProblemCycle {
+		t.Fatalf("expected one cycle problem, got %v", problems)
+	}
+
+	if problems[0].Message != "cycle detected: a -> b -> c -> a" {
+		t.Fatalf("unexpected message %s", problems[0].Message)
+	}
+}
+
+func TestValidateDuplicateAndEmptyName(t *testing.T) {
+	rs := testRecipes()
+	rs["other-base"] = Recipe{Name: "base", Inherits: "archlinux/base", InheritsExternal: true}
+	rs["unnamed"] = Recipe{Inherits: "base"}
+
+	problems := Validate(rs)
+	if len(problems) != 2 {
+		t.Fatalf("expected two problems, got %v", problems)
+	}
+
+	kinds := []ProblemKind{problems[0].Kind, problems[1].Kind}
+	if !reflect.DeepEqual(kinds, []ProblemKind{ProblemDuplicate, ProblemEmptyName}) {
+		t.Fatalf("unexpected problems %v", problems)
+	}
+}
+
+func TestValidateExpected(t *testing.T) {
+	rs := testRecipes()
+
+	problems := ValidateExpected(rs, []string{"base", "missing"}, false)
+	if len(problems) != 1 || problems[0].Kind != ProblemMissingExpected || problems[0].Recipes[0] != "missing" {
+		t.Fatalf("unexpected problems %v", problems)
+	}
+
+	problems = ValidateExpected(rs, []string{"base", "base-common", "desktop", "server", "web", "tools"}, true)
+	if len(problems) != 1 || problems[0].Kind != ProblemUnexpected || problems[0].Recipes[0] != "tools-extra" {
+		t.Fatalf("unexpected problems %v", problems)
+	}
+}
+
+func TestValidateSingleBase(t *testing.T) {
+	rs := testRecipes()
+
+	problems := ValidateSingleBase(rs)
+	if len(problems) != 1 || problems[0].Kind != ProblemMultipleBases {
+		t.Fatalf("expected a multiple bases problem, got %v", problems)
+	}
+
+	if problems[0].Message != "expected a single external image, found 2: archlinux/base, ubuntu:20.04" {
+		t.Fatalf("unexpected message %s", problems[0].Message)
+	}
+
+	delete(rs, "tools")
+	delete(rs, "tools-extra")
+	if problems = ValidateSingleBase(rs); len(problems) != 0 {
+		t.Fatalf("expected no problems, got %v", problems)
+	}
+}
+
+func TestValidateNamePattern(t *testing.T) {
+	rs := testRecipes()
+
+	problems, err := ValidateNamePattern(rs, "[a-z]+(-[a-z]+)*")
+	if err != nil {
+		t.Fatalf("error validating names %v", err)
+	}
+	if len(problems) != 0 {
+		t.Fatalf("expected no problems, got %v", problems)
+	}
+
+	rs["Web App"] = Recipe{Name: "Web App", Inherits: "base"}
+	rs["web2"] = Recipe{Name: "web2", Inherits: "base"}
+
+	problems, err = ValidateNamePattern(rs, "[a-z]+(-[a-z]+)*")
+	if err != nil {
+		t.Fatalf("error validating names %v", err)
+	}
+	if len(problems) != 2 || problems[0].Recipes[0] != "Web App" || problems[1].Recipes[0] != "web2" {
+		t.Fatalf("unexpected problems %v", problems)
+	}
+	if problems[0].Kind != ProblemNamePattern {
+		t.Fatalf("expected %s, got %s", ProblemNamePattern, problems[0].Kind)
+	}
+
+	// The pattern must match the whole name.
+	problems, err = ValidateNamePattern(rs, "web")
+	if err != nil {
+		t.Fatalf("error validating names %v", err)
+	}
+	if len(problems) != len(rs)-1 {
+		t.Fatalf("expected all recipes but web to fail, got %v", problems)
+	}
+
+	if _, err = ValidateNamePattern(rs, "("); err == nil {
+		t.Fatal("expected an error for an invalid pattern")
+	}
+}
+
+func TestValidateConfusableNames(t *testing.T) {
+	rs := testRecipes()
+
+	if problems := ValidateConfusableNames(rs); len(problems) != 0 {
+		t.Fatalf("expected no problems, got %v", problems)
+	}
+
+	rs["WebServer"] = Recipe{Name: "WebServer", Inherits: "base"}
+	rs["web-server"] = Recipe{Name: "web-server", Inherits: "base"}
+	rs["web_server"] = Recipe{Name: "web_server", Inherits: "base"}
+
+	problems := ValidateConfusableNames(rs)
+	if len(problems) != 1 || problems[0].Kind != ProblemConfusableNames {
+		t.Fatalf("unexpected problems %v", problems)
+	}
+	expected := []string{"WebServer", "web-server", "web_server"}
+	if !reflect.DeepEqual(problems[0].Recipes, expected) {
+		t.Fatalf("expected %v, got %v", expected, problems[0].Recipes)
+	}
+}
+
+func TestValidateResolveTime(t *testing.T) {
+	rs := testRecipes()
+
+	times := TimeResolveChains(rs)
+	if len(times) != len(rs) {
+		t.Fatalf("expected a time for every recipe, got %v", times)
+	}
+	if problems := ValidateResolveTime(times, time.Hour); len(problems) != 0 {
+		t.Fatalf("expected no problems, got %v", problems)
+	}
+
+	times = []ResolveTime{
+		{Name: "base", Duration: time.Millisecond},
+		{Name: "web", Duration: 3 * time.Second},
+		{Name: "desktop", Duration: 2 * time.Second},
+	}
+	problems := ValidateResolveTime(times, time.Second)
+	if len(problems) != 2 || problems[0].Recipes[0] != "web" || problems[1].Recipes[0] != "desktop" {
+		t.Fatalf("unexpected problems %v", problems)
+	}
+	if problems[0].Kind != ProblemSlowResolve {
+		t.Fatalf("expected %s, got %s", ProblemSlowResolve, problems[0].Kind)
+	}
+}
+
+func TestProblemKindExplanation(t *testing.T) {
+	kinds := []ProblemKind{
+		ProblemEmptyName,
+		ProblemDuplicate,
+		ProblemSelfInheritance,
+		ProblemMissingParent,
+		ProblemCycle,
+		ProblemMissingExpected,
+		ProblemUnexpected,
+		ProblemMultipleBases,
+		ProblemNamePattern,
+		ProblemUnreadable,
+		ProblemInvalid,
+		ProblemSlowResolve,
+		ProblemConfusableNames,
+	}
+
+	for _, kind := range kinds {
+		if len(kind.Explanation()) == 0 {
+			t.Fatalf("expected an explanation for %s", kind)
+		}
+	}
+}
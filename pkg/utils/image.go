@@ -0,0 +1,45 @@
+package utils
+
+import "strings"
+
+// NormalizeImageRef Expands an image reference to its fully qualified form,
+// the way docker does, so that equivalent references compare equal. For
+// example, ubuntu becomes docker.io/library/ubuntu:latest.
+func NormalizeImageRef(ref string) string {
+	name, digest := ref, ""
+	if i := strings.Index(name, "@"); i >= 0 {
+		name, digest = name[:i], name[i:]
+	}
+
+	tag := ""
+	// A colon after the last slash starts the tag, before it is a registry port.
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, tag = name[:i], name[i:]
+	}
+	if len(tag) == 0 && len(digest) == 0 {
+		tag = ":latest"
+	}
+
+	domain, remainder := "docker.io", name
+	if i := strings.Index(name, "/"); i >= 0 {
+		first := name[:i]
+		if strings.ContainsAny(first, ".:") || first == "localhost" {
+			domain, remainder = first, name[i+1:]
+		}
+	}
+	if domain == "index.docker.io" {
+		domain = "docker.io"
+	}
+	if domain == "docker.io" && !strings.Contains(remainder, "/") {
+		remainder = "library/" + remainder
+	}
+
+	return domain + "/" + remainder + tag + digest
+}
+
+// ImageRegistry Returns the registry host of an image reference, which is
+// docker.io for references without an explicit registry.
+func ImageRegistry(ref string) string {
+	normalized := NormalizeImageRef(ref)
+	return normalized[:strings.Index(normalized, "/")]
+}
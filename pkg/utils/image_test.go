@@ -0,0 +1,40 @@
+package utils
+
+import "testing"
+
+func TestNormalizeImageRef(t *testing.T) {
+	tests := map[string]string{
+		"ubuntu":                          "docker.io/library/ubuntu:latest",
+		"ubuntu:20.04":                    "docker.io/library/ubuntu:20.04",
+		"docker.io/library/ubuntu:latest": "docker.io/library/ubuntu:latest",
+		"index.docker.io/library/ubuntu":  "docker.io/library/ubuntu:latest",
+		"archlinux/base":                  "docker.io/archlinux/base:latest",
+		"registry:5000/team/web":          "registry:5000/team/web:latest",
+		"localhost/web:dev":               "localhost/web:dev",
+		"quay.io/coreos/etcd@sha256:abc":  "quay.io/coreos/etcd@sha256:abc",
+		"ubuntu:20.04@sha256:abc":         "docker.io/library/ubuntu:20.04@sha256:abc",
+	}
+
+	for ref, expected := range tests {
+		if normalized := NormalizeImageRef(ref); normalized != expected {
+			t.Fatalf("expected %s for %s, got %s", expected, ref, normalized)
+		}
+	}
+}
+
+func TestImageRegistry(t *testing.T) {
+	tests := map[string]string{
+		"ubuntu":                         "docker.io",
+		"archlinux/base":                 "docker.io",
+		"index.docker.io/library/ubuntu": "docker.io",
+		"gcr.io/distroless/base":         "gcr.io",
+		"registry:5000/team/web":         "registry:5000",
+		"localhost/web:dev":              "localhost",
+	}
+
+	for ref, expected := range tests {
+		if registry := ImageRegistry(ref); registry != expected {
+			t.Fatalf("expected %s for %s, got %s", expected, ref, registry)
+		}
+	}
+}
@@ -12,8 +12,12 @@ import (
 	"strings"
 )
 
-// ExpandPath Expands the given path to an absolute directory
+// ExpandPath Expands the given path to an absolute directory. A leading ~ is
+// replaced with the user's home directory.
 func ExpandPath(pathToExpand string) string {
+	if pathToExpand == "~" || strings.HasPrefix(pathToExpand, "~/") {
+		pathToExpand = path.Join(os.Getenv("HOME"), pathToExpand[1:])
+	}
 	if !path.IsAbs(pathToExpand) {
 		wd, err := os.Getwd()
 		if err != nil {